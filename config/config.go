@@ -86,10 +86,26 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, ".proxy")
 	}
 
+	for name, q := range c.Logs.Queries {
+		if q == nil {
+			return errors.Errorf(".logs.queries.%s: query required", name)
+		}
+	}
+
+	if err := c.Logs.Validate(); err != nil {
+		return errors.Wrap(err, ".logs")
+	}
+
 	if err := c.Stages.Validate(); err != nil {
 		return errors.Wrap(err, ".stages")
 	}
 
+	for name, q := range c.Logs.Queries {
+		if q.Stage != "" && c.Stages.GetByName(q.Stage) == nil {
+			return errors.Errorf(".logs.queries.%s.stage: stage %q does not exist", name, q.Stage)
+		}
+	}
+
 	if len(c.Regions) > 1 {
 		return errors.New("multiple regions is not yet supported, see https://github.com/apex/up/issues/134")
 	}
@@ -72,6 +72,41 @@ func TestConfig_Type(t *testing.T) {
 	})
 }
 
+func TestConfig_Logs(t *testing.T) {
+	t.Run("valid query stage", func(t *testing.T) {
+		c := Config{
+			Name: "api",
+			Logs: Logs{
+				Queries: map[string]*Query{
+					"errors": {Query: "error", Stage: "production"},
+				},
+			},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("invalid query stage", func(t *testing.T) {
+		c := Config{
+			Name: "api",
+			Logs: Logs{
+				Queries: map[string]*Query{
+					"errors": {Query: "error", Stage: "prod"},
+				},
+			},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.logs.queries.errors.stage: stage "prod" does not exist`)
+	})
+
+	t.Run("null query", func(t *testing.T) {
+		_, err := ParseConfigString(`{ "name": "api", "logs": { "queries": { "errors": null } } }`)
+		assert.EqualError(t, err, `validating: .logs.queries.errors: query required`)
+	})
+}
+
 func TestConfig_Regions(t *testing.T) {
 	t.Skip()
 
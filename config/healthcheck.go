@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HealthCheck config.
+type HealthCheck struct {
+	// Path requested to check the app's health, disabled when empty.
+	Path string `json:"path"`
+
+	// Status code expected in the response.
+	Status int `json:"status"`
+
+	// Interval between checks while the app is running.
+	Interval Duration `json:"interval"`
+
+	// Timeout for each check.
+	Timeout Duration `json:"timeout"`
+}
+
+// Enabled returns true if the health check is enabled.
+func (h *HealthCheck) Enabled() bool {
+	return h.Path != ""
+}
+
+// Default implementation.
+func (h *HealthCheck) Default() error {
+	if h.Status == 0 {
+		h.Status = 200
+	}
+
+	if h.Interval == 0 {
+		h.Interval = Duration(5 * time.Second)
+	}
+
+	if h.Timeout == 0 {
+		h.Timeout = Duration(2 * time.Second)
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (h *HealthCheck) Validate() error {
+	if !h.Enabled() {
+		return nil
+	}
+
+	if !strings.HasPrefix(h.Path, "/") {
+		err := errors.New("must begin with /")
+		return errors.Wrap(err, ".path")
+	}
+
+	if h.Status < 100 || h.Status > 599 {
+		err := errors.New("must be a valid status code")
+		return errors.Wrap(err, ".status")
+	}
+
+	if h.Interval < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".interval")
+	}
+
+	if h.Timeout < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".timeout")
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestHealthCheck_Default(t *testing.T) {
+	a := &HealthCheck{}
+	assert.NoError(t, a.Default(), "default")
+
+	b := &HealthCheck{
+		Status:   200,
+		Interval: Duration(5 * time.Second),
+		Timeout:  Duration(2 * time.Second),
+	}
+
+	assert.Equal(t, b, a)
+	assert.False(t, a.Enabled(), "enabled")
+}
+
+func TestHealthCheck_Validate(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		c := &HealthCheck{}
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		c := &HealthCheck{Path: "/health"}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		c := &HealthCheck{Path: "health"}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.path: must begin with /`)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		c := &HealthCheck{Path: "/health", Status: 1000}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.status: must be a valid status code`)
+	})
+}
@@ -1,5 +1,15 @@
 package config
 
+import (
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
 // Logs configuration.
 type Logs struct {
 	// Disable json log output.
@@ -10,6 +20,47 @@ type Logs struct {
 
 	// Stderr default log level.
 	Stderr string `json:"stderr"`
+
+	// Format of the app's log output, parsed into structured logs.
+	Format string `json:"format"`
+
+	// Levels maps level names to patterns matching plain text lines
+	// logged at that level, in addition to the built-in markers.
+	Levels map[string][]string `json:"levels"`
+
+	// Redact configuration for scrubbing sensitive values.
+	Redact Redact `json:"redact"`
+
+	// RequestFields is a list of additional fields logged for each
+	// request, such as "user_agent" or "header:X-Forwarded-For".
+	RequestFields []string `json:"request_fields"`
+
+	// DisableRequestLine disables the "request" log line,
+	// leaving only the "response" line for each request.
+	DisableRequestLine bool `json:"disable_request_line"`
+
+	// SampleRate is the fraction of successful responses logged, where
+	// 0 logs none, client and server errors are always logged.
+	SampleRate *float64 `json:"sample_rate"`
+
+	// AccessFormat of request logs, structured logs and/or
+	// NCSA Common or Combined access log lines.
+	AccessFormat string `json:"access_format"`
+
+	// Queries maps names to saved queries for up logs.
+	Queries map[string]*Query `json:"queries"`
+}
+
+// queryName is the pattern for saved query names.
+var queryName = regexp.MustCompile(`^[\w-]+$`)
+
+// requestFields available by name, in addition
+// to the "header:" and "response_header:" prefixes.
+var requestFields = []string{
+	"user_agent",
+	"referer",
+	"host",
+	"stage",
 }
 
 // Default implementation.
@@ -22,5 +73,91 @@ func (l *Logs) Default() error {
 		l.Stderr = "error"
 	}
 
+	if l.Format == "" {
+		l.Format = "auto"
+	}
+
+	if l.SampleRate == nil {
+		rate := 1.0
+		l.SampleRate = &rate
+	}
+
+	if l.AccessFormat == "" {
+		l.AccessFormat = "structured"
+	}
+
+	if err := l.Redact.Default(); err != nil {
+		return errors.Wrap(err, ".redact")
+	}
+
 	return nil
 }
+
+// Validate implementation.
+func (l *Logs) Validate() error {
+	if err := validate.List(l.Format, []string{"auto", "json", "logfmt"}); err != nil {
+		return errors.Wrap(err, ".format")
+	}
+
+	for name, patterns := range l.Levels {
+		if _, err := log.ParseLevel(name); err != nil {
+			return errors.Wrapf(err, ".levels.%s", name)
+		}
+
+		for _, s := range patterns {
+			if _, err := regexp.Compile(s); err != nil {
+				return errors.Wrapf(err, ".levels.%s", name)
+			}
+		}
+	}
+
+	if err := l.Redact.Validate(); err != nil {
+		return errors.Wrap(err, ".redact")
+	}
+
+	for i, s := range l.RequestFields {
+		if err := validateRequestField(s); err != nil {
+			return errors.Wrapf(err, ".request_fields: at index %d", i)
+		}
+	}
+
+	if r := l.SampleRate; r != nil && (*r < 0 || *r > 1) {
+		err := errors.New("must be in [0, 1]")
+		return errors.Wrap(err, ".sample_rate")
+	}
+
+	if err := validate.List(l.AccessFormat, []string{"structured", "common", "combined", "both"}); err != nil {
+		return errors.Wrap(err, ".access_format")
+	}
+
+	for name, q := range l.Queries {
+		if !queryName.MatchString(name) {
+			return errors.Errorf(".queries: %q is invalid, names may contain only letters, digits, underscores, and dashes", name)
+		}
+
+		if q == nil {
+			return errors.Errorf(".queries.%s: query required", name)
+		}
+
+		if err := q.Validate(); err != nil {
+			return errors.Wrapf(err, ".queries.%s", name)
+		}
+	}
+
+	return nil
+}
+
+// validateRequestField validates a request field name.
+func validateRequestField(s string) error {
+	for _, prefix := range []string{"header:", "response_header:"} {
+		if strings.HasPrefix(s, prefix) {
+			if strings.TrimPrefix(s, prefix) == "" {
+				return errors.New("header name is required")
+			}
+
+			return nil
+		}
+	}
+
+	return validate.List(s, requestFields)
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestLogs_Default(t *testing.T) {
+	a := &Logs{}
+	assert.NoError(t, a.Default(), "default")
+
+	rate := 1.0
+	b := &Logs{
+		Stdout:       "info",
+		Stderr:       "error",
+		Format:       "auto",
+		Redact:       Redact{Mode: "mask"},
+		SampleRate:   &rate,
+		AccessFormat: "structured",
+	}
+
+	assert.Equal(t, b, a)
+}
+
+func TestLogs_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := &Logs{Format: "logfmt"}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		c := &Logs{Format: "xml"}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".format: \"xml\" is invalid, must be one of:\n\n  • auto\n  • json\n  • logfmt")
+	})
+	t.Run("invalid level", func(t *testing.T) {
+		c := &Logs{Levels: map[string][]string{"loud": {"x"}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".levels.loud: invalid level")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		c := &Logs{Levels: map[string][]string{"warn": {"("}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".levels.warn: error parsing regexp: missing closing ): `(`")
+	})
+
+	t.Run("valid request fields", func(t *testing.T) {
+		c := &Logs{RequestFields: []string{"user_agent", "stage", "header:X-Forwarded-For", "response_header:Content-Type"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("invalid request field", func(t *testing.T) {
+		c := &Logs{RequestFields: []string{"host", "cookies"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".request_fields: at index 1: \"cookies\" is invalid, must be one of:\n\n  • user_agent\n  • referer\n  • host\n  • stage")
+	})
+
+	t.Run("missing header name", func(t *testing.T) {
+		c := &Logs{RequestFields: []string{"header:"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".request_fields: at index 0: header name is required")
+	})
+
+	t.Run("invalid access format", func(t *testing.T) {
+		c := &Logs{AccessFormat: "apache"}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".access_format: \"apache\" is invalid, must be one of:\n\n  • structured\n  • common\n  • combined\n  • both")
+	})
+
+	t.Run("invalid query name", func(t *testing.T) {
+		c := &Logs{Queries: map[string]*Query{"slow requests": {Query: "duration > 1s"}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.queries: "slow requests" is invalid, names may contain only letters, digits, underscores, and dashes`)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		c := &Logs{Queries: map[string]*Query{"slow": {Query: "duration >"}}}
+		assert.NoError(t, c.Default(), "default")
+		assert.Contains(t, c.Validate().Error(), `.queries.slow: .query: unexpected end of query`)
+	})
+
+	t.Run("zero sample rate", func(t *testing.T) {
+		rate := 0.0
+		c := &Logs{SampleRate: &rate}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, 0.0, *c.SampleRate)
+	})
+
+	t.Run("invalid sample rate", func(t *testing.T) {
+		rate := 1.5
+		c := &Logs{SampleRate: &rate}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".sample_rate: must be in [0, 1]")
+	})
+}
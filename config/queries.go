@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs/parser"
+	"github.com/apex/up/internal/util"
+)
+
+// Query is a saved log query, which may be specified
+// as a query string or an object with defaults.
+type Query struct {
+	// Query is the log query.
+	Query string `json:"query"`
+
+	// Since is the default duration of logs shown.
+	Since string `json:"since"`
+
+	// Stage is the default stage of logs shown.
+	Stage string `json:"stage"`
+
+	// Expand is used to show expanded logs by default.
+	Expand bool `json:"expand"`
+}
+
+// UnmarshalJSON implementation.
+func (q *Query) UnmarshalJSON(b []byte) error {
+	switch b[0] {
+	case '"':
+		return json.Unmarshal(b, &q.Query)
+	case '{':
+		type query Query
+		return json.Unmarshal(b, (*query)(q))
+	default:
+		return errors.New("query must be a string or object")
+	}
+}
+
+// Validate implementation.
+func (q *Query) Validate() error {
+	if strings.TrimSpace(q.Query) == "" {
+		return errors.New(".query: is required")
+	}
+
+	if _, err := parser.Parse(q.Query); err != nil {
+		return errors.Wrap(err, ".query")
+	}
+
+	if q.Since != "" {
+		if _, err := util.ParseTime(q.Since, time.Now()); err != nil {
+			return errors.Wrap(err, ".since")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestQuery(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		var q Query
+		err := json.Unmarshal([]byte(`"duration > 1s"`), &q)
+		assert.NoError(t, err, "unmarshal")
+		assert.Equal(t, Query{Query: "duration > 1s"}, q)
+	})
+
+	t.Run("object", func(t *testing.T) {
+		var q Query
+		err := json.Unmarshal([]byte(`{ "query": "error", "since": "3d", "stage": "production", "expand": true }`), &q)
+		assert.NoError(t, err, "unmarshal")
+		assert.Equal(t, Query{Query: "error", Since: "3d", Stage: "production", Expand: true}, q)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		var q Query
+		err := json.Unmarshal([]byte(`5`), &q)
+		assert.EqualError(t, err, `query must be a string or object`)
+	})
+}
+
+func TestQuery_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		q := &Query{Query: "status >= 500 | stats count() by path", Since: "2h"}
+		assert.NoError(t, q.Validate(), "validate")
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		q := &Query{Since: "2h"}
+		assert.EqualError(t, q.Validate(), `.query: is required`)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		q := &Query{Query: "status >="}
+		assert.EqualError(t, q.Validate(), ".query: unexpected end of query at column 10, expected a value\n\n  status >=\n           ^")
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		q := &Query{Query: "error", Since: "last week"}
+		err := q.Validate()
+		assert.Error(t, err, "validate")
+		assert.Contains(t, err.Error(), `.since: "last week" must be a duration`)
+	})
+}
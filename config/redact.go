@@ -0,0 +1,61 @@
+package config
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/validate"
+)
+
+// Redact config for scrubbing sensitive values from logs.
+type Redact struct {
+	// Fields is a list of field names whose values are redacted.
+	Fields []string `json:"fields"`
+
+	// Query is a list of query string parameters whose values are redacted.
+	Query []string `json:"query"`
+
+	// Patterns is a list of regular expressions redacted from messages and values.
+	Patterns []string `json:"patterns"`
+
+	// Mode of redaction, "mask" replaces values, while
+	// "hash" replaces them with a hash so they may be correlated.
+	Mode string `json:"mode"`
+
+	// Key is the secret used to hash values, when empty a random
+	// key is generated each time the app starts.
+	Key string `json:"key"`
+}
+
+// Default implementation.
+func (r *Redact) Default() error {
+	if r.Mode == "" {
+		r.Mode = "mask"
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (r *Redact) Validate() error {
+	if err := validate.List(r.Mode, []string{"mask", "hash"}); err != nil {
+		return errors.Wrap(err, ".mode")
+	}
+
+	if err := validate.RequiredStrings(r.Fields); err != nil {
+		return errors.Wrap(err, ".fields")
+	}
+
+	if err := validate.RequiredStrings(r.Query); err != nil {
+		return errors.Wrap(err, ".query")
+	}
+
+	for i, s := range r.Patterns {
+		if _, err := regexp.Compile(s); err != nil {
+			return errors.Wrapf(err, ".patterns at index %d", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/tj/assert"
+)
+
+func TestRedact_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := &Redact{
+			Fields:   []string{"email"},
+			Query:    []string{"token"},
+			Patterns: []string{`\d{16}`},
+		}
+
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+		assert.Equal(t, "mask", c.Mode)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		c := &Redact{Mode: "encrypt"}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".mode: \"encrypt\" is invalid, must be one of:\n\n  • mask\n  • hash")
+	})
+
+	t.Run("empty field", func(t *testing.T) {
+		c := &Redact{Fields: []string{"email", " "}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".fields: at index 1: is required")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		c := &Redact{Patterns: []string{"("}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), ".patterns at index 0: error parsing regexp: missing closing ): `(`")
+	})
+}
@@ -14,6 +14,32 @@ type Relay struct {
 
 	// ListenTimeout in seconds when waiting for the app to bind to PORT.
 	ListenTimeout int `json:"listen_timeout"`
+
+	// MaxIdleConns is the maximum number of idle keep-alive connections to the app.
+	MaxIdleConns int `json:"max_idle_conns"`
+
+	// IdleTimeout in seconds before an idle keep-alive connection to the app is closed.
+	IdleTimeout int `json:"idle_timeout"`
+
+	// Socket enables a unix socket exposed to the app as UP_SOCKET,
+	// which is used in place of PORT.
+	Socket bool `json:"socket"`
+
+	// Workers is the number of app processes to run and balance requests across.
+	Workers int `json:"workers"`
+
+	// ShutdownTimeout in seconds to wait for in-flight requests to drain
+	// when stopping the app, before it is forcefully killed.
+	ShutdownTimeout int `json:"shutdown_timeout"`
+
+	// HealthCheck configuration for the app's readiness and liveness.
+	HealthCheck HealthCheck `json:"health_check"`
+
+	// Backoff configuration for restarting the app when it crashes.
+	Backoff Backoff `json:"backoff"`
+
+	// Watch configuration for reloading the app on changes in development.
+	Watch Watch `json:"watch"`
 }
 
 // Default implementation.
@@ -30,6 +56,34 @@ func (r *Relay) Default() error {
 		r.ListenTimeout = 15
 	}
 
+	if r.MaxIdleConns == 0 {
+		r.MaxIdleConns = 10
+	}
+
+	if r.IdleTimeout == 0 {
+		r.IdleTimeout = 2
+	}
+
+	if r.Workers == 0 {
+		r.Workers = 1
+	}
+
+	if r.ShutdownTimeout == 0 {
+		r.ShutdownTimeout = 15
+	}
+
+	if err := r.HealthCheck.Default(); err != nil {
+		return errors.Wrap(err, ".health_check")
+	}
+
+	if err := r.Backoff.Default(); err != nil {
+		return errors.Wrap(err, ".backoff")
+	}
+
+	if err := r.Watch.Default(); err != nil {
+		return errors.Wrap(err, ".watch")
+	}
+
 	return nil
 }
 
@@ -50,11 +104,44 @@ func (r *Relay) Validate() error {
 		return errors.Wrap(err, ".listen_timeout")
 	}
 
+	if r.MaxIdleConns < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".max_idle_conns")
+	}
+
+	if r.IdleTimeout < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".idle_timeout")
+	}
+
+	if r.Workers < 1 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".workers")
+	}
+
+	if r.ShutdownTimeout < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".shutdown_timeout")
+	}
+
+	if r.ShutdownTimeout > 25 {
+		err := errors.New("should be <= 25")
+		return errors.Wrap(err, ".shutdown_timeout")
+	}
+
 	if r.Timeout > 25 {
 		err := errors.New("should be <= 25")
 		return errors.Wrap(err, ".timeout")
 	}
 
+	if err := r.HealthCheck.Validate(); err != nil {
+		return errors.Wrap(err, ".health_check")
+	}
+
+	if err := r.Watch.Validate(); err != nil {
+		return errors.Wrap(err, ".watch")
+	}
+
 	return nil
 }
 
@@ -78,6 +78,12 @@ func runtimeConfig(runtime Runtime, c *Config) error {
 func golang(c *Config) {
 	if c.Hooks.Build.IsEmpty() {
 		c.Hooks.Build = Hook{`GOOS=linux GOARCH=amd64 go build -o server *.go`}
+
+		// the build hook cross-compiles for Lambda, so build for the
+		// local platform when restarting the app with `up start`
+		if c.Proxy.Watch.Hook.IsEmpty() {
+			c.Proxy.Watch.Hook = Hook{`go build -o server *.go`}
+		}
 	}
 
 	if c.Hooks.Clean.IsEmpty() {
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Watch config for reloading the app on changes with `up start`.
+type Watch struct {
+	// Enable watching, also enabled with `up start --watch`.
+	Enable bool `json:"enable"`
+
+	// Paths is a list of .upignore style patterns watched, all files are watched when empty.
+	Paths []string `json:"paths"`
+
+	// Ignore is a list of .upignore style patterns ignored in addition to .upignore.
+	Ignore []string `json:"ignore"`
+
+	// Build runs the build hook before restarting the app.
+	Build bool `json:"build"`
+
+	// Hook is run instead of the build hook when Build is enabled,
+	// for example to build for the local platform.
+	Hook Hook `json:"hook"`
+
+	// Interval between polls for changes.
+	Interval Duration `json:"interval"`
+
+	// Debounce is the delay without further changes before reloading.
+	Debounce Duration `json:"debounce"`
+}
+
+// Default implementation.
+func (w *Watch) Default() error {
+	if w.Interval == 0 {
+		w.Interval = Duration(500 * time.Millisecond)
+	}
+
+	if w.Debounce == 0 {
+		w.Debounce = Duration(250 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// Validate implementation.
+func (w *Watch) Validate() error {
+	for i, s := range w.Paths {
+		if s == "" {
+			err := errors.New("should not be empty")
+			return errors.Wrapf(err, ".paths[%d]", i)
+		}
+	}
+
+	for i, s := range w.Ignore {
+		if s == "" {
+			err := errors.New("should not be empty")
+			return errors.Wrapf(err, ".ignore[%d]", i)
+		}
+	}
+
+	if w.Interval < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".interval")
+	}
+
+	if w.Debounce < 0 {
+		err := errors.New("should be greater than 0")
+		return errors.Wrap(err, ".debounce")
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+)
+
+func TestWatch_Default(t *testing.T) {
+	a := &Watch{}
+	assert.NoError(t, a.Default(), "default")
+
+	b := &Watch{
+		Interval: Duration(500 * time.Millisecond),
+		Debounce: Duration(250 * time.Millisecond),
+	}
+
+	assert.Equal(t, b, a)
+}
+
+func TestWatch_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := &Watch{Paths: []string{"**/*.js"}, Ignore: []string{"test"}}
+		assert.NoError(t, c.Default(), "default")
+		assert.NoError(t, c.Validate(), "validate")
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		c := &Watch{Paths: []string{"*.js", ""}}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.paths[1]: should not be empty`)
+	})
+
+	t.Run("invalid debounce", func(t *testing.T) {
+		c := &Watch{Debounce: Duration(-time.Second)}
+		assert.NoError(t, c.Default(), "default")
+		assert.EqualError(t, c.Validate(), `.debounce: should be greater than 0`)
+	})
+}
@@ -5,6 +5,7 @@ package handler
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -34,6 +35,32 @@ func FromConfig(c *up.Config) (http.Handler, error) {
 	}
 }
 
+// Swap is a handler which may be replaced while serving.
+type Swap struct {
+	mu sync.RWMutex
+	h  http.Handler
+}
+
+// NewSwap returns a swappable handler serving `h`.
+func NewSwap(h http.Handler) *Swap {
+	return &Swap{h: h}
+}
+
+// Set the handler.
+func (s *Swap) Set(h http.Handler) {
+	s.mu.Lock()
+	s.h = h
+	s.mu.Unlock()
+}
+
+// ServeHTTP implementation.
+func (s *Swap) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	h := s.h
+	s.mu.RUnlock()
+	h.ServeHTTP(w, r)
+}
+
 // New handler complete with all Up middleware.
 func New(c *up.Config, h http.Handler) (http.Handler, error) {
 	h = poweredby.New("up", h)
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"bufio"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -48,6 +51,40 @@ func TestNode(t *testing.T) {
 	assert.Equal(t, header, actual)
 }
 
+func TestNodeUpgrade(t *testing.T) {
+	os.Chdir("testdata/node-upgrade")
+	defer os.Chdir("../..")
+
+	c, err := up.ReadConfig("up.json")
+	assert.NoError(t, err, "read config")
+
+	h := newHandler(t, c)
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	assert.NoError(t, err, "dial")
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "GET /socket HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+	assert.NoError(t, err, "write request")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	assert.NoError(t, err, "read response")
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "echo", res.Header.Get("Upgrade"))
+
+	_, err = io.WriteString(conn, "hello")
+	assert.NoError(t, err, "write")
+
+	b := make([]byte, 5)
+	_, err = io.ReadFull(br, b)
+	assert.NoError(t, err, "read")
+	assert.Equal(t, "hello", string(b))
+}
+
 func TestStatic(t *testing.T) {
 	os.Chdir("testdata/static")
 	defer os.Chdir("../..")
@@ -211,3 +248,23 @@ func TestHandler_spa(t *testing.T) {
 		assert.Equal(t, "bar css\n", res.Body.String())
 	})
 }
+
+func TestSwap(t *testing.T) {
+	text := func(s string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, s)
+		})
+	}
+
+	h := NewSwap(text("first"))
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "first", res.Body.String())
+
+	h.Set(text("second"))
+
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "second", res.Body.String())
+}
@@ -0,0 +1,14 @@
+const http = require('http')
+const port = process.env.PORT
+
+const server = http.createServer((req, res) => {
+  res.setHeader('Content-Type', 'text/html')
+  res.end('<html><head></head><body>Hello World</body></html>')
+}).listen(port, '127.0.0.1', _ => {
+  console.log('listening')
+})
+
+server.on('upgrade', (req, socket) => {
+  socket.write('HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n')
+  socket.pipe(socket)
+})
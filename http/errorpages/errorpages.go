@@ -4,7 +4,9 @@
 package errorpages
 
 import (
+	"bufio"
 	"io"
+	"net"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -82,6 +84,24 @@ func (r *response) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Flush implementation.
+func (r *response) Flush() {
+	if !r.header {
+		r.WriteHeader(200)
+	}
+
+	if r.ignore {
+		return
+	}
+
+	util.Flush(r.ResponseWriter)
+}
+
+// Hijack implementation.
+func (r *response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return util.Hijack(r.ResponseWriter)
+}
+
 // New error pages handler.
 func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	pages, err := errorpage.Load(c.ErrorPages.Dir)
@@ -225,3 +225,37 @@ func doesNotAcceptHTML(h http.Handler) func(t *testing.T) {
 		assert.Equal(t, `{ "error": "bad_request" }`, res.Body.String())
 	}
 }
+
+func TestErrors_streaming(t *testing.T) {
+	c := &up.Config{
+		Name: "app",
+		ErrorPages: config.ErrorPages{
+			Dir: "testdata/templates",
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+	assert.NoError(t, c.Validate(), "validate")
+
+	res := httptest.NewRecorder()
+
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		assert.True(t, res.Flushed, "flushed")
+		assert.Equal(t, "data: hello\n\n", res.Body.String())
+		io.WriteString(w, "data: world\n\n")
+	})
+
+	h, err := New(c, stream)
+	assert.NoError(t, err, "init")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html")
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, "data: hello\n\ndata: world\n\n", res.Body.String())
+}
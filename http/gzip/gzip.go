@@ -7,9 +7,130 @@ import (
 	"github.com/NYTimes/gziphandler"
 
 	"github.com/apex/up"
+	"github.com/apex/up/internal/util"
 )
 
+// response modes.
+const (
+	undecided = iota
+	compressed
+	streamed
+)
+
+// response wrapper deciding whether to compress from the response, streamed
+// responses are passed through to the client uncompressed. A response is
+// streamed when its media type is streaming, or when it is flushed before
+// enough of the body is written to be compressed.
+type response struct {
+	// ResponseWriter is the compressing writer.
+	http.ResponseWriter
+
+	// raw is the client's writer.
+	raw http.ResponseWriter
+
+	buf  []byte
+	code int
+	mode int
+}
+
+// WriteHeader implementation.
+func (r *response) WriteHeader(code int) {
+	r.code = code
+
+	if util.IsStreaming(r.Header().Get("Content-Type")) {
+		r.decide(streamed)
+	}
+}
+
+// Write implementation.
+func (r *response) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	switch r.mode {
+	case streamed:
+		return r.raw.Write(b)
+	case compressed:
+		return r.ResponseWriter.Write(b)
+	}
+
+	r.buf = append(r.buf, b...)
+
+	if len(r.buf) >= gziphandler.DefaultMinSize {
+		if err := r.decide(compressed); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush implementation.
+func (r *response) Flush() {
+	if r.mode == undecided {
+		r.decide(streamed)
+	}
+
+	util.Flush(r.writer())
+}
+
+// end writes the pending response once the handler returns.
+func (r *response) end() {
+	if r.mode == undecided {
+		r.decide(compressed)
+	}
+}
+
+// decide to compress or stream the response, writing the pending header and body.
+func (r *response) decide(mode int) error {
+	r.mode = mode
+	w := r.writer()
+
+	if r.code != 0 {
+		w.WriteHeader(r.code)
+	}
+
+	if len(r.buf) == 0 {
+		return nil
+	}
+
+	_, err := w.Write(r.buf)
+	r.buf = nil
+	return err
+}
+
+// writer returns the writer of the response mode.
+func (r *response) writer() http.ResponseWriter {
+	if r.mode == streamed {
+		return r.raw
+	}
+
+	return r.ResponseWriter
+}
+
 // New gzip handler.
 func New(c *up.Config, next http.Handler) http.Handler {
-	return gziphandler.GzipHandler(next)
+	gzip := gziphandler.GzipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client does not accept gzip
+		gw, ok := w.(*gziphandler.GzipResponseWriter)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res := &response{ResponseWriter: gw, raw: gw.ResponseWriter}
+		next.ServeHTTP(res, r)
+		res.end()
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// upgraded connections are not compressed
+		if util.IsUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gzip.ServeHTTP(w, r)
+	})
 }
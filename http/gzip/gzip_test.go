@@ -3,6 +3,7 @@ package gzip
 import (
 	"compress/gzip"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -66,3 +67,60 @@ func TestGzip(t *testing.T) {
 		assert.Equal(t, body, res.Body.String())
 	})
 }
+
+func TestGzip_streaming(t *testing.T) {
+	c, err := up.ParseConfigString(`{ "name": "app" }`)
+	assert.NoError(t, err, "config")
+
+	t.Run("event stream", func(t *testing.T) {
+		res := httptest.NewRecorder()
+
+		h := New(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data: hello\n\n")
+			w.(http.Flusher).Flush()
+			assert.True(t, res.Flushed, "flushed")
+			assert.Equal(t, "data: hello\n\n", res.Body.String())
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "*/*")
+		req.Header.Set("Accept-Encoding", "gzip")
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assert.Empty(t, res.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("flushed", func(t *testing.T) {
+		res := httptest.NewRecorder()
+
+		h := New(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "chunk 1\n")
+			w.(http.Flusher).Flush()
+			assert.True(t, res.Flushed, "flushed")
+			assert.Equal(t, "chunk 1\n", res.Body.String())
+			io.WriteString(w, "chunk 2\n")
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assert.Empty(t, res.Header().Get("Content-Encoding"))
+		assert.Equal(t, "chunk 1\nchunk 2\n", res.Body.String())
+	})
+
+	t.Run("accepts event stream", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		New(c, hello).ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/apex/up"
 	"github.com/apex/up/internal/inject"
+	"github.com/apex/up/internal/util"
 )
 
 // response wrapper.
@@ -29,6 +30,10 @@ func (r *response) Write(b []byte) (int, error) {
 		return r.Write(b)
 	}
 
+	if r.ignore {
+		return r.ResponseWriter.Write(b)
+	}
+
 	return r.body.Write(b)
 }
 
@@ -37,8 +42,31 @@ func (r *response) WriteHeader(code int) {
 	r.header = true
 	w := r.ResponseWriter
 	kind := w.Header().Get("Content-Type")
-	r.ignore = !strings.HasPrefix(kind, "text/html") || code >= 300
+	r.ignore = !strings.HasPrefix(kind, "text/html") || code >= 300 || util.IsStreaming(kind)
 	r.code = code
+
+	// responses which are not injected are passed through
+	// unbuffered, so that they may be streamed
+	if r.ignore {
+		w.WriteHeader(code)
+	}
+}
+
+// Flush implementation, flushed responses are
+// streamed, so they are no longer injected.
+func (r *response) Flush() {
+	if !r.header {
+		r.WriteHeader(200)
+	}
+
+	if !r.ignore {
+		r.ignore = true
+		r.ResponseWriter.WriteHeader(r.code)
+		r.ResponseWriter.Write(r.body.Bytes())
+		r.body.Reset()
+	}
+
+	util.Flush(r.ResponseWriter)
 }
 
 // end injects if necessary.
@@ -46,8 +74,6 @@ func (r *response) end() {
 	w := r.ResponseWriter
 
 	if r.ignore {
-		w.WriteHeader(r.code)
-		r.body.WriteTo(w)
 		return
 	}
 
@@ -63,6 +89,12 @@ func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	}
 
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// upgraded connections have no body to inject into
+		if util.IsUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		res := &response{ResponseWriter: w, rules: c.Inject}
 		next.ServeHTTP(res, r)
 		res.end()
@@ -108,3 +108,78 @@ func TestInject(t *testing.T) {
 		assert.Equal(t, "<html><head>  <script src=\"/whatever.js\"></script>\n  </head><body></body></html>", res.Body.String())
 	})
 }
+
+func TestInject_streaming(t *testing.T) {
+	c := &up.Config{
+		Name: "app",
+		Inject: inject.Rules{
+			"head": []*inject.Rule{
+				{
+					Type:  "script",
+					Value: "/whatever.js",
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+	assert.NoError(t, c.Validate(), "validate")
+
+	res := httptest.NewRecorder()
+
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		assert.True(t, res.Flushed, "flushed")
+		assert.Equal(t, "data: hello\n\n", res.Body.String())
+		io.WriteString(w, "data: world\n\n")
+	})
+
+	h, err := New(c, stream)
+	assert.NoError(t, err, "init")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, "data: hello\n\ndata: world\n\n", res.Body.String())
+}
+
+func TestInject_flushed(t *testing.T) {
+	c := &up.Config{
+		Name: "app",
+		Inject: inject.Rules{
+			"head": []*inject.Rule{
+				{
+					Type:  "script",
+					Value: "/whatever.js",
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+	assert.NoError(t, c.Validate(), "validate")
+
+	res := httptest.NewRecorder()
+
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html><head></head>")
+		w.(http.Flusher).Flush()
+		assert.True(t, res.Flushed, "flushed")
+		assert.Equal(t, "<html><head></head>", res.Body.String())
+		io.WriteString(w, "<body></body></html>")
+	})
+
+	h, err := New(c, stream)
+	assert.NoError(t, err, "init")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "<html><head></head><body></body></html>", res.Body.String())
+}
@@ -0,0 +1,104 @@
+package logs
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// field is an optional request log field.
+type field struct {
+	// name of the log field.
+	name string
+
+	// header is the response header name, for response fields.
+	header string
+
+	// value returns the field value from the request, for request fields.
+	value func(r *http.Request) string
+}
+
+// fields is a list of optional request log fields.
+type fields []field
+
+// request fields available by name.
+var requestFields = map[string]func(r *http.Request) string{
+	"user_agent": func(r *http.Request) string { return r.UserAgent() },
+	"referer":    func(r *http.Request) string { return r.Referer() },
+	"host":       func(r *http.Request) string { return r.Host },
+	"stage":      func(r *http.Request) string { return os.Getenv("UP_STAGE") },
+}
+
+// compileFields returns fields from names such as "user_agent",
+// "header:X-Forwarded-For" or "response_header:Content-Type".
+func compileFields(names []string) (list fields, err error) {
+	for _, s := range names {
+		switch {
+		case strings.HasPrefix(s, "header:"):
+			name := strings.TrimPrefix(s, "header:")
+			list = append(list, field{
+				name: fieldName(name),
+				value: func(r *http.Request) string {
+					return r.Header.Get(name)
+				},
+			})
+		case strings.HasPrefix(s, "response_header:"):
+			name := strings.TrimPrefix(s, "response_header:")
+			list = append(list, field{
+				name:   "response_" + fieldName(name),
+				header: name,
+			})
+		case requestFields[s] != nil:
+			list = append(list, field{
+				name:  s,
+				value: requestFields[s],
+			})
+		default:
+			return nil, errors.Errorf("unknown request field %q", s)
+		}
+	}
+
+	return
+}
+
+// request returns the request fields present.
+func (f fields) request(r *http.Request) log.Fields {
+	m := log.Fields{}
+
+	for _, field := range f {
+		if field.value == nil {
+			continue
+		}
+
+		if v := field.value(r); v != "" {
+			m[field.name] = v
+		}
+	}
+
+	return m
+}
+
+// response returns the response header fields present.
+func (f fields) response(h http.Header) log.Fields {
+	m := log.Fields{}
+
+	for _, field := range f {
+		if field.header == "" {
+			continue
+		}
+
+		if v := h.Get(field.header); v != "" {
+			m[field.name] = v
+		}
+	}
+
+	return m
+}
+
+// fieldName returns a log field name for the header, such as "x_forwarded_for".
+func fieldName(header string) string {
+	return strings.Replace(strings.ToLower(header), "-", "_", -1)
+}
@@ -2,25 +2,36 @@
 package logs
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/apex/log"
+	"github.com/pkg/errors"
 
 	"github.com/apex/up"
 	"github.com/apex/up/internal/logs"
+	"github.com/apex/up/internal/logs/access"
+	"github.com/apex/up/internal/logs/redact"
+	"github.com/apex/up/internal/proxy"
 	"github.com/apex/up/internal/util"
 )
 
-// TODO: optional verbose mode with req/res header etc?
-
 // log context.
 var ctx = logs.Plugin("logs")
 
 // response wrapper.
 type response struct {
 	http.ResponseWriter
+	log      log.Interface
 	written  int
 	code     int
 	duration time.Duration
@@ -39,36 +50,161 @@ func (r *response) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implementation.
+func (r *response) Flush() {
+	util.Flush(r.ResponseWriter)
+}
+
+// Hijack implementation.
+func (r *response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := util.Hijack(r.ResponseWriter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.code = http.StatusSwitchingProtocols
+	r.log.Info("tunnel opened")
+
+	t := &tunnel{
+		Conn:  conn,
+		log:   r.log,
+		start: time.Now(),
+	}
+
+	return t, rw, nil
+}
+
+// tunnel is a hijacked connection, which
+// is logged with its lifetime once closed.
+type tunnel struct {
+	net.Conn
+	log      log.Interface
+	start    time.Time
+	received int64
+	sent     int64
+	once     sync.Once
+}
+
+// Read implementation.
+func (t *tunnel) Read(b []byte) (int, error) {
+	n, err := t.Conn.Read(b)
+	atomic.AddInt64(&t.received, int64(n))
+	return n, err
+}
+
+// Write implementation.
+func (t *tunnel) Write(b []byte) (int, error) {
+	n, err := t.Conn.Write(b)
+	atomic.AddInt64(&t.sent, int64(n))
+	return n, err
+}
+
+// Close implementation.
+func (t *tunnel) Close() error {
+	err := t.Conn.Close()
+
+	t.once.Do(func() {
+		t.log.WithFields(log.Fields{
+			"duration": util.MillisecondsSince(t.start),
+			"received": atomic.LoadInt64(&t.received),
+			"sent":     atomic.LoadInt64(&t.sent),
+		}).Info("tunnel closed")
+	})
+
+	return err
+}
+
+// random returns a number in [0, 1) used for sampling.
+var random = rand.Float64
+
+// accessLog is the writer for access log lines.
+var accessLog io.Writer = os.Stdout
+
 // New logs handler.
 func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	if c.Logs.Disable {
 		return next, nil
 	}
 
+	redactor, err := redact.New(c.Logs.Redact)
+	if err != nil {
+		return nil, errors.Wrap(err, "redact")
+	}
+
+	fields, err := compileFields(c.Logs.RequestFields)
+	if err != nil {
+		return nil, errors.Wrap(err, "request fields")
+	}
+
+	rate := 1.0
+	if c.Logs.SampleRate != nil {
+		rate = *c.Logs.SampleRate
+	}
+
+	requestLine := !c.Logs.DisableRequestLine
+
+	// the request line is deferred until the response
+	// is known when sampling, so they are dropped together
+	sampling := rate < 1
+
+	format := c.Logs.AccessFormat
+	structured := format == "" || format == "structured" || format == "both"
+
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := logContext(r)
-		logRequest(ctx, r)
+		ctx := logContext(r, fields, redactor)
+
+		if structured && requestLine && !sampling {
+			logRequest(ctx, r)
+		}
 
 		start := time.Now()
-		res := &response{ResponseWriter: w, code: 200}
+		res := &response{ResponseWriter: w, log: ctx, code: 200}
 		next.ServeHTTP(res, r)
 		res.duration = time.Since(start)
 
-		logResponse(ctx, res, r)
+		if sampling {
+			if res.code < 400 && random() >= rate {
+				return
+			}
+
+			if structured && requestLine {
+				logRequest(ctx, r)
+			}
+
+			if res.code < 400 {
+				ctx = ctx.WithField("sample_rate", rate)
+			}
+		}
+
+		if structured {
+			ctx = ctx.WithFields(redactor.Fields(fields.response(w.Header())))
+			logResponse(ctx, res, r)
+		}
+
+		switch format {
+		case "common":
+			fmt.Fprintln(accessLog, accessLine(r, res, start, redactor).Common())
+		case "combined", "both":
+			fmt.Fprintln(accessLog, accessLine(r, res, start, redactor).Combined())
+		}
 	})
 
 	return h, nil
 }
 
-// logContext returns the common log context for a request.
-func logContext(r *http.Request) log.Interface {
-	return ctx.WithFields(log.Fields{
-		"id":     r.Header.Get("X-Request-Id"),
-		"method": r.Method,
-		"path":   r.URL.Path,
-		"query":  r.URL.Query().Encode(),
-		"ip":     r.RemoteAddr,
-	})
+// logContext returns the common log context for a request,
+// with sensitive values redacted.
+func logContext(r *http.Request, fields fields, redactor *redact.Redactor) log.Interface {
+	f := fields.request(r)
+	f["id"] = r.Header.Get("X-Request-Id")
+	if id := proxy.LambdaRequestID(r.Context()); id != "" {
+		f["lambda_id"] = id
+	}
+	f["method"] = r.Method
+	f["path"] = r.URL.Path
+	f["query"] = redactor.Query(r.URL.Query()).Encode()
+	f["ip"] = r.RemoteAddr
+	return ctx.WithFields(redactor.Fields(f))
 }
 
 // logRequest logs the request.
@@ -83,6 +219,42 @@ func logRequest(ctx log.Interface, r *http.Request) {
 	ctx.Info("request")
 }
 
+// accessLine returns the access log line for a request,
+// with sensitive values redacted.
+func accessLine(r *http.Request, res *response, start time.Time, redactor *redact.Redactor) access.Line {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	user, _, _ := r.BasicAuth()
+
+	f := redactor.Fields(log.Fields{
+		"ip":         ip,
+		"user":       user,
+		"referer":    r.Referer(),
+		"user_agent": r.UserAgent(),
+	})
+
+	uri := r.URL.EscapedPath()
+	if q := redactor.Query(r.URL.Query()).Encode(); q != "" {
+		uri += "?" + q
+	}
+
+	return access.Line{
+		IP:        fmt.Sprint(f["ip"]),
+		User:      fmt.Sprint(f["user"]),
+		Time:      start,
+		Method:    r.Method,
+		URI:       uri,
+		Protocol:  r.Proto,
+		Status:    res.code,
+		Size:      res.written,
+		Referer:   fmt.Sprint(f["referer"]),
+		UserAgent: fmt.Sprint(f["user_agent"]),
+	}
+}
+
 // logResponse logs the response.
 func logResponse(ctx log.Interface, res *response, r *http.Request) {
 	ctx = ctx.WithFields(log.Fields{
@@ -1,9 +1,14 @@
 package logs
 
 import (
+	"bufio"
 	"bytes"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/tj/assert"
@@ -11,6 +16,7 @@ import (
 	"github.com/apex/up"
 	"github.com/apex/up/config"
 	"github.com/apex/up/http/static"
+	"github.com/apex/up/internal/proxy"
 )
 
 func TestLogs(t *testing.T) {
@@ -48,4 +54,324 @@ func TestLogs(t *testing.T) {
 	assert.Contains(t, s, `plugin=logs`)
 	assert.Contains(t, s, `size=11`)
 	assert.Contains(t, s, `status=200`)
+	assert.NotContains(t, s, `lambda_id`)
+}
+
+func TestLogs_lambda(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	c := &up.Config{
+		Static: config.Static{
+			Dir: "testdata",
+		},
+	}
+
+	h, err := New(c, static.New(c))
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-Id", "8ff53267")
+	req = req.WithContext(proxy.WithLambdaRequestID(req.Context(), "3f5c0a4e"))
+
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+
+	s := buf.String()
+	assert.Contains(t, s, `info request`)
+	assert.Contains(t, s, `info response`)
+	assert.Contains(t, s, `id=8ff53267`)
+	assert.Contains(t, s, `lambda_id=3f5c0a4e`)
+}
+
+func TestLogs_upgrade(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	c := &up.Config{}
+
+	closed := make(chan struct{})
+
+	h, err := New(c, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		assert.NoError(t, err, "hijack")
+
+		io.WriteString(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+
+		b := make([]byte, 5)
+		io.ReadFull(rw, b)
+		conn.Write(b)
+		conn.Close()
+	}))
+	assert.NoError(t, err)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		close(closed)
+	}))
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	assert.NoError(t, err, "dial")
+	defer conn.Close()
+
+	io.WriteString(conn, "GET /socket HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	assert.NoError(t, err, "read response")
+	assert.Equal(t, 101, res.StatusCode)
+
+	io.WriteString(conn, "hello")
+	b := make([]byte, 5)
+	io.ReadFull(br, b)
+	assert.Equal(t, "hello", string(b))
+
+	<-closed
+	out := buf.String()
+	assert.Contains(t, out, `info tunnel opened`)
+	assert.Contains(t, out, `info tunnel closed`)
+	assert.Contains(t, out, `sent=5`)
+	assert.Contains(t, out, `status=101`)
+}
+
+func TestLogs_streaming(t *testing.T) {
+	c := &up.Config{}
+
+	res := httptest.NewRecorder()
+
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		assert.True(t, res.Flushed, "flushed")
+		assert.Equal(t, "data: hello\n\n", res.Body.String())
+		io.WriteString(w, "data: world\n\n")
+	})
+
+	h, err := New(c, stream)
+	assert.NoError(t, err, "init")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, "data: hello\n\ndata: world\n\n", res.Body.String())
+}
+
+func TestLogs_redact(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	c := &up.Config{
+		Static: config.Static{
+			Dir: "testdata",
+		},
+		Logs: config.Logs{
+			Redact: config.Redact{
+				Fields: []string{"ip"},
+				Query:  []string{"token"},
+				Mode:   "mask",
+			},
+		},
+	}
+
+	h, err := New(c, static.New(c))
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/?token=secret&page=2", nil)
+
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+
+	s := buf.String()
+	assert.NotContains(t, s, `secret`)
+	assert.NotContains(t, s, `192.0.2.1`)
+	assert.Contains(t, s, `query=page=2&token=%5BREDACTED%5D`)
+	assert.Contains(t, s, `ip=[REDACTED]`)
+}
+
+func TestLogs_requestFields(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	os.Setenv("UP_STAGE", "staging")
+	defer os.Unsetenv("UP_STAGE")
+
+	c := &up.Config{
+		Static: config.Static{
+			Dir: "testdata",
+		},
+		Logs: config.Logs{
+			RequestFields: []string{
+				"user_agent",
+				"host",
+				"stage",
+				"header:X-Forwarded-For",
+				"response_header:Content-Type",
+			},
+			DisableRequestLine: true,
+		},
+	}
+
+	h, err := New(c, static.New(c))
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", "curl")
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+
+	s := buf.String()
+	assert.NotContains(t, s, `info request`)
+	assert.Contains(t, s, `info response`)
+	assert.Contains(t, s, `user_agent=curl`)
+	assert.Contains(t, s, `host=example.com`)
+	assert.Contains(t, s, `stage=staging`)
+	assert.Contains(t, s, `x_forwarded_for=1.2.3.4`)
+	assert.Contains(t, s, `response_content_type=text/html; charset=utf-8`)
+}
+
+func TestLogs_sampling(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	defer func(f func() float64) { random = f }(random)
+
+	rate := 0.25
+	c := &up.Config{
+		Static: config.Static{
+			Dir: "testdata",
+		},
+		Logs: config.Logs{
+			SampleRate: &rate,
+		},
+	}
+
+	h, err := New(c, static.New(c))
+	assert.NoError(t, err)
+
+	t.Run("dropped", func(t *testing.T) {
+		buf.Reset()
+		random = func() float64 { return 0.5 }
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, 200, res.Code)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		buf.Reset()
+		random = func() float64 { return 0.1 }
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, 200, res.Code)
+
+		s := buf.String()
+		assert.Contains(t, s, `info request`)
+		assert.Contains(t, s, `info response`)
+		assert.Contains(t, s, `sample_rate=0.25`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		buf.Reset()
+		random = func() float64 { return 0.5 }
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/missing", nil))
+		assert.Equal(t, 404, res.Code)
+
+		s := buf.String()
+		assert.Contains(t, s, `info request`)
+		assert.Contains(t, s, `warn response`)
+		assert.NotContains(t, s, `sample_rate`)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		buf.Reset()
+		rate = 0
+		random = func() float64 { return 0 }
+		defer func() { rate = 0.25 }()
+
+		h, err := New(c, static.New(c))
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, 200, res.Code)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestLogs_accessFormat(t *testing.T) {
+	var buf, lines bytes.Buffer
+	log.SetOutput(&buf)
+
+	defer func(w io.Writer) { accessLog = w }(accessLog)
+	accessLog = &lines
+
+	t.Run("combined", func(t *testing.T) {
+		buf.Reset()
+		lines.Reset()
+
+		c := &up.Config{
+			Static: config.Static{
+				Dir: "testdata",
+			},
+			Logs: config.Logs{
+				AccessFormat: "combined",
+				Redact: config.Redact{
+					Query: []string{"token"},
+					Mode:  "mask",
+				},
+			},
+		}
+
+		h, err := New(c, static.New(c))
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/?token=secret", nil)
+		req.Header.Set("User-Agent", "curl")
+		req.SetBasicAuth("tobi", "ferret")
+
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Code)
+
+		assert.Empty(t, buf.String())
+		assert.Regexp(t, `^192\.0\.2\.1 - tobi \[.+\] "GET /\?token=%5BREDACTED%5D HTTP/1\.1" 200 11 "-" "curl"\n$`, lines.String())
+	})
+
+	t.Run("both", func(t *testing.T) {
+		buf.Reset()
+		lines.Reset()
+
+		c := &up.Config{
+			Static: config.Static{
+				Dir: "testdata",
+			},
+			Logs: config.Logs{
+				AccessFormat: "both",
+			},
+		}
+
+		h, err := New(c, static.New(c))
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/missing", nil))
+		assert.Equal(t, 404, res.Code)
+
+		assert.Contains(t, buf.String(), `warn response`)
+		assert.Regexp(t, `^192\.0\.2\.1 - - \[.+\] "GET /missing HTTP/1\.1" 404 \d+ "-" "-"\n$`, lines.String())
+	})
 }
@@ -2,13 +2,16 @@
 package redirects
 
 import (
+	"bufio"
 	"fmt"
+	"net"
 	"net/http"
 
 	"github.com/apex/log"
 	"github.com/apex/up"
 	"github.com/apex/up/internal/logs"
 	"github.com/apex/up/internal/redirect"
+	"github.com/apex/up/internal/util"
 )
 
 // TODO: tests for popagating 4xx / 5xx, dont mask all these
@@ -50,6 +53,24 @@ func (r *rewrite) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Flush implementation.
+func (r *rewrite) Flush() {
+	if !r.header {
+		r.WriteHeader(200)
+	}
+
+	if r.isNotFound {
+		return
+	}
+
+	util.Flush(r.ResponseWriter)
+}
+
+// Hijack implementation.
+func (r *rewrite) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return util.Hijack(r.ResponseWriter)
+}
+
 // New redirects handler.
 func New(c *up.Config, next http.Handler) (http.Handler, error) {
 	if len(c.Redirects) == 0 {
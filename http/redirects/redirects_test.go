@@ -2,6 +2,7 @@ package redirects
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -197,3 +198,35 @@ func test(t *testing.T, h http.Handler) {
 		assert.Equal(t, "admin /admin/login", res.Body.String())
 	})
 }
+
+func TestRedirects_streaming(t *testing.T) {
+	c := &up.Config{
+		Redirects: redirect.Rules{
+			"/": {
+				Location: "/index.html",
+				Status:   200,
+			},
+		},
+	}
+
+	res := httptest.NewRecorder()
+
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		assert.True(t, res.Flushed, "flushed")
+		assert.Equal(t, "data: hello\n\n", res.Body.String())
+		io.WriteString(w, "data: world\n\n")
+	})
+
+	h, err := New(c, stream)
+	assert.NoError(t, err, "init")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "text/event-stream", res.Header().Get("Content-Type"))
+	assert.Equal(t, "data: hello\n\ndata: world\n\n", res.Body.String())
+}
@@ -0,0 +1,110 @@
+package relay
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tj/backoff"
+
+	"github.com/apex/up/internal/util"
+)
+
+// checkHealth requests the health check path of the app at `u`,
+// returning an error unless the expected status is returned.
+func (w *worker) checkHealth(u *url.URL) error {
+	c := w.proxy.config.Proxy.HealthCheck
+
+	res, err := w.health.Get(u.String() + c.Path)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode != c.Status {
+		return errors.Errorf("expected status %d, got %d", c.Status, res.StatusCode)
+	}
+
+	return nil
+}
+
+// waitForHealthy blocks until the app at `u` is healthy with timeout.
+func (w *worker) waitForHealthy(u *url.URL, timeout time.Duration) error {
+	timedout := time.After(timeout)
+
+	b := backoff.Backoff{
+		Min:    100 * time.Millisecond,
+		Max:    time.Second,
+		Factor: 1.5,
+	}
+
+	for {
+		err := w.checkHealth(u)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-timedout:
+			return errors.Wrapf(err, "timed out after %s", timeout)
+		case <-time.After(b.Duration()):
+		}
+	}
+}
+
+// monitor checks the health of `cmd` periodically, restarting
+// it when unhealthy, until the process exits.
+func (w *worker) monitor(cmd *process, u *url.URL) {
+	t := time.NewTicker(time.Duration(w.proxy.config.Proxy.HealthCheck.Interval))
+	defer t.Stop()
+
+	for {
+		select {
+		case <-cmd.exited:
+			return
+		case <-t.C:
+			err := w.checkHealth(u)
+			if err == nil {
+				w.healthy()
+				continue
+			}
+
+			w.log.WithError(err).Warn("app unhealthy")
+			atomic.CompareAndSwapInt64(&w.unhealthy, 0, time.Now().UnixNano())
+
+			if err := w.restartAfterCrash(cmd); err != nil {
+				w.log.WithError(err).Error("restarting")
+			}
+
+			return
+		}
+	}
+}
+
+// healthy logs the recovery of the app when it was found unhealthy,
+// such as by the health checks of the process it replaced.
+func (w *worker) healthy() {
+	since := atomic.SwapInt64(&w.unhealthy, 0)
+	if since == 0 {
+		return
+	}
+
+	w.log.WithField("duration", util.MillisecondsSince(time.Unix(0, since))).Info("app recovered")
+}
+
+// newHealthClient returns a client for health checks.
+func newHealthClient(timeout time.Duration, dial dialFunc) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:       dial,
+			DisableKeepAlives: true,
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package relay
+
+import (
+	"io"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// process is a child process of the app.
+type process struct {
+	*exec.Cmd
+
+	// served is non-zero once the process has responded.
+	served int32
+
+	// inflight tracks the requests being served by the process.
+	inflight sync.WaitGroup
+
+	// draining is true once the process is being stopped, and
+	// is guarded by the worker's lock.
+	draining bool
+
+	// exited is closed when the process exits.
+	exited chan struct{}
+}
+
+// newProcess returns a process for cmd.
+func newProcess(cmd *exec.Cmd) *process {
+	setpgid(cmd)
+	return &process{
+		Cmd:    cmd,
+		exited: make(chan struct{}),
+	}
+}
+
+// Start the process.
+func (p *process) Start() error {
+	if err := p.Cmd.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		p.Wait()
+		close(p.exited)
+	}()
+
+	return nil
+}
+
+// serve marks the process as having responded.
+func (p *process) serve() {
+	atomic.StoreInt32(&p.served, 1)
+}
+
+// Served returns true if the process has responded.
+func (p *process) Served() bool {
+	return atomic.LoadInt32(&p.served) == 1
+}
+
+// ExitedWithin returns true if the process exits within timeout.
+func (p *process) ExitedWithin(timeout time.Duration) bool {
+	select {
+	case <-p.exited:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Stop the process gracefully, sending SIGTERM to its process group and
+// waiting for in-flight requests to drain and the process to exit. The
+// process group is killed when this takes longer than timeout.
+func (p *process) Stop(timeout time.Duration) error {
+	// the process may have exited already
+	terminate(p.Cmd)
+
+	drained := make(chan struct{})
+
+	go func() {
+		p.inflight.Wait()
+		<-p.exited
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-time.After(timeout):
+		if err := kill(p.Cmd); err != nil {
+			return errors.Wrap(err, "killing")
+		}
+
+		return errors.Errorf("timed out after %s", timeout)
+	}
+}
+
+// tunnel is an upgraded response body which invokes done once closed.
+type tunnel struct {
+	io.ReadWriteCloser
+	once sync.Once
+	done func()
+}
+
+// Close implementation.
+func (t *tunnel) Close() error {
+	err := t.ReadWriteCloser.Close()
+	t.once.Do(t.done)
+	return err
+}
+
+// body is a response body which invokes done once closed.
+type body struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+// Close implementation.
+func (b *body) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
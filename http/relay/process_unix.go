@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package relay
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setpgid places the command in its own process group, so that
+// signals reach any sub-processes it spawns, such as `go run`.
+func setpgid(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminate sends SIGTERM to the process group of cmd.
+func terminate(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// kill sends SIGKILL to the process group of cmd.
+func kill(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
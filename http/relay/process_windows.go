@@ -0,0 +1,18 @@
+package relay
+
+import (
+	"os/exec"
+)
+
+// setpgid is a no-op, process groups are unsupported.
+func setpgid(cmd *exec.Cmd) {}
+
+// terminate kills the process, as signals are unsupported.
+func terminate(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// kill the process.
+func kill(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
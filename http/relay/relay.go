@@ -4,56 +4,66 @@
 package relay
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
 	"os"
-	"os/exec"
-	"sync"
+	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"github.com/apex/log"
-	"github.com/facebookgo/freeport"
+	"github.com/golang/sync/errgroup"
 	"github.com/pkg/errors"
 
 	"github.com/apex/up"
 	"github.com/apex/up/internal/logs"
+	"github.com/apex/up/internal/logs/redact"
 	"github.com/apex/up/internal/logs/writer"
-	"github.com/apex/up/internal/util"
 )
 
+// dialer used to connect to the app.
+var dialer = &net.Dialer{
+	Timeout:   2 * time.Second,
+	KeepAlive: 2 * time.Second,
+	DualStack: true,
+}
+
+// dialFunc is a function dialing the app.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
 // log context.
 var ctx = logs.Plugin("relay")
 
+// errCrashLoop is returned when the app crashed more
+// times in a row than the backoff attempts permit.
+var errCrashLoop = errors.New("app is crash-looping")
+
+// errShutdown is returned for requests once the app is shut down.
+var errShutdown = errors.New("app is shut down")
+
+// idempotent methods which are retried after a restart.
+var idempotent = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"OPTIONS": true,
+}
+
 // Proxy is a reverse proxy and sub-process monitor
 // for ensuring your web server is running.
 type Proxy struct {
 	config *up.Config
 
-	// transport used for the reverse proxy.
-	transport http.RoundTripper
+	// workers running the app.
+	workers []*worker
 
-	// stdout is the log writer for structured logging output.
-	stdout *writer.Writer
-
-	// stderr is the log writer for structured logging output.
-	stderr *writer.Writer
-
-	mu sync.Mutex
-
-	// restarts is the restart count.
-	restarts int
-
-	// url is the active application url.
-	url *url.URL
+	// next is the offset used to break ties when balancing.
+	next uint32
 
 	// ReverseProxy is the reverse proxy making the requests to the app.
 	*httputil.ReverseProxy
-
-	// cmd is the current child process of the app.
-	cmd *exec.Cmd
 }
 
 // New proxy.
@@ -72,23 +82,53 @@ func New(c *up.Config) (http.Handler, error) {
 		return nil, errors.Wrap(err, "invalid stderr log level")
 	}
 
-	timeout := time.Duration(c.Proxy.Timeout) * time.Second
+	parser, err := writer.ParserFor(c.Logs.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid log format")
+	}
+
+	levels, err := writer.CompileLevels(c.Logs.Levels)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid log levels")
+	}
 
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   2 * time.Second,
-			KeepAlive: 2 * time.Second,
-			DualStack: true,
-		}).DialContext,
-		ResponseHeaderTimeout: timeout,
-		DisableKeepAlives:     true,
+	redactor, err := redact.New(c.Logs.Redact)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid log redaction")
 	}
 
 	p := &Proxy{
-		config:    c,
-		stdout:    writer.New(stdout, ctx),
-		stderr:    writer.New(stderr, ctx),
-		transport: transport,
+		config: c,
+	}
+
+	p.ReverseProxy = &httputil.ReverseProxy{
+		Director:     director,
+		Transport:    p,
+		ErrorHandler: p.errorHandler,
+	}
+
+	for id := 0; id < c.Proxy.Workers; id++ {
+		log := ctx
+		if c.Proxy.Workers > 1 {
+			log = ctx.WithField("worker", id)
+		}
+
+		w := &worker{
+			id:      id,
+			proxy:   p,
+			log:     log,
+			stdout:  newWriter(stdout, log, parser, levels, redactor),
+			stderr:  newWriter(stderr, log, parser, levels, redactor),
+			backoff: c.Proxy.Backoff.Backoff(),
+		}
+
+		if c.Proxy.Socket {
+			w.socket = socketPath(id)
+		}
+
+		w.transport = newTransport(c, w.dial)
+		w.health = newHealthClient(time.Duration(c.Proxy.HealthCheck.Timeout), w.dial)
+		p.workers = append(p.workers, w)
 	}
 
 	if err := p.Start(); err != nil {
@@ -98,119 +138,120 @@ func New(c *up.Config) (http.Handler, error) {
 	return p, nil
 }
 
-// Start the server.
+// Start the servers.
 func (p *Proxy) Start() error {
-	if err := p.startServer(); err != nil {
-		return err
-	}
-
-	p.ReverseProxy = httputil.NewSingleHostReverseProxy(p.url)
-	p.ReverseProxy.Transport = p
+	var g errgroup.Group
 
-	start := time.Now()
-	timeout := time.Duration(p.config.Proxy.ListenTimeout) * time.Second
-	ctx.Info("waiting for app to listen on PORT")
-
-	if err := util.WaitForListen(p.url, timeout); err != nil {
-		return errors.Wrapf(err, "waiting for %s to be in listening state", p.url.String())
+	for _, w := range p.workers {
+		w := w
+		g.Go(w.Start)
 	}
 
-	ctx.WithField("duration", util.MillisecondsSince(start)).Info("app listening")
-	return nil
+	return g.Wait()
 }
 
-// Restart the server.
+// Restart the servers, one at a time so that the
+// remaining workers continue serving requests.
 func (p *Proxy) Restart() error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	ctx.Warn("restarting")
-	p.restarts++
-
-	if p.cmd != nil {
-		if err := p.cmd.Process.Kill(); err != nil {
-			ctx.WithError(err).Error("killing application process")
+	for _, w := range p.workers {
+		if err := w.Restart(); err != nil {
+			return errors.Wrapf(err, "worker %d", w.id)
 		}
 	}
 
-	if err := p.Start(); err != nil {
-		return err
+	return nil
+}
+
+// Shutdown the servers gracefully.
+func (p *Proxy) Shutdown() error {
+	var g errgroup.Group
+
+	for _, w := range p.workers {
+		w := w
+		g.Go(w.Shutdown)
 	}
 
-	ctx.WithField("restarts", p.restarts).Warn("restarted")
-	return nil
+	return g.Wait()
 }
 
 // RoundTrip implementation.
 func (p *Proxy) RoundTrip(r *http.Request) (*http.Response, error) {
-	id := r.Header.Get("X-Request-Id")
-	ctx = ctx.WithField("id", id)
-
-	res, err := p.transport.RoundTrip(r)
+	return p.pick().RoundTrip(r)
+}
 
-	// timeout error
-	if e, ok := err.(net.Error); ok && e.Timeout() {
-		ctx.WithError(err).Warn("request timeout")
-		return res, err
-	}
+// pick returns the ready worker with the least in-flight requests,
+// ties are broken in a round-robin fashion. Workers which are restarting
+// or crash-looping are only picked when no worker is ready.
+func (p *Proxy) pick() *worker {
+	n := len(p.workers)
+	offset := int(atomic.AddUint32(&p.next, 1))
 
-	// temporary error
-	if e, ok := err.(net.Error); ok && e.Temporary() {
-		ctx.WithError(err).Warn("request temporary error")
-		return res, err
-	}
+	var best *worker
+	for i := 0; i < n; i++ {
+		w := p.workers[(offset+i)%n]
 
-	// network error
-	if err != nil {
-		ctx.WithError(err).Error("request network error")
-		if err := p.Restart(); err != nil {
-			ctx.WithError(err).Error("restarting")
+		if best == nil || w.rank() < best.rank() {
+			best = w
 		}
 	}
 
-	return res, err
+	return best
 }
 
-// environment returns the server env variables.
-func (p *Proxy) environment() []string {
-	return []string{
-		env("PORT", p.url.Port()),
-		env("UP_RESTARTS", p.restarts),
+// errorHandler responds with 503 when the app is crash-looping,
+// and 502 for any other error from the app.
+func (p *Proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if err == errCrashLoop {
+		http.Error(w, "Application crashed repeatedly and is unavailable.", http.StatusServiceUnavailable)
+		return
 	}
+
+	w.WriteHeader(http.StatusBadGateway)
 }
 
-// startServer the server on a free port.
-func (p *Proxy) startServer() error {
-	port, err := freeport.Get()
-	if err != nil {
-		return errors.Wrap(err, "getting free port")
+// newTransport returns a transport for requests to the app, reusing
+// a bounded pool of idle keep-alive connections.
+func newTransport(c *up.Config, dial dialFunc) *http.Transport {
+	return &http.Transport{
+		DialContext:           dial,
+		ResponseHeaderTimeout: time.Duration(c.Proxy.Timeout) * time.Second,
+		MaxIdleConns:          c.Proxy.MaxIdleConns,
+		MaxIdleConnsPerHost:   c.Proxy.MaxIdleConns,
+		IdleConnTimeout:       time.Duration(c.Proxy.IdleTimeout) * time.Second,
 	}
+}
 
-	target, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", port))
-	if err != nil {
-		return errors.Wrap(err, "parsing url")
-	}
+// newWriter returns a log writer for the app's output.
+func newWriter(l log.Level, ctx log.Interface, p writer.Parser, levels writer.Levels, r *redact.Redactor) *writer.Writer {
+	return writer.New(l, ctx).
+		WithParser(p).
+		WithLevels(levels).
+		WithRedactor(r)
+}
 
-	p.url = target
+// socketPath returns the unix socket path for the given worker.
+func socketPath(id int) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("up-%d-%d.sock", os.Getpid(), id))
+}
 
-	ctx.WithField("command", p.config.Proxy.Command).WithField("PORT", port).Info("starting app")
-	p.cmd = p.command(p.config.Proxy.Command, p.environment())
+// director prepares requests for the app, the
+// host is assigned by the worker serving it.
+func director(r *http.Request) {
+	r.URL.Scheme = "http"
 
-	if err := p.cmd.Start(); err != nil {
-		return errors.Wrap(err, "running command")
+	// explicitly disable User-Agent so it's not set to default value
+	if _, ok := r.Header["User-Agent"]; !ok {
+		r.Header.Set("User-Agent", "")
 	}
-
-	ctx.Info("started app")
-	return nil
 }
 
-// command returns the command for spawning a server.
-func (p *Proxy) command(s string, env []string) *exec.Cmd {
-	cmd := exec.Command("sh", "-c", s)
-	cmd.Stdout = p.stdout
-	cmd.Stderr = p.stderr
-	cmd.Env = append(os.Environ(), append(env, "PATH=node_modules/.bin:"+os.Getenv("PATH"))...)
-	return cmd
+// isRetryable returns true if the request may be safely retried.
+func isRetryable(r *http.Request) bool {
+	if !idempotent[r.Method] {
+		return false
+	}
+
+	return r.Body == nil || r.Body == http.NoBody
 }
 
 // env returns an environment variable.
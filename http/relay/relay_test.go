@@ -1,10 +1,18 @@
 package relay
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -67,8 +75,7 @@ func TestRelay(t *testing.T) {
     "host": "example.com",
     "user-agent": "tobi",
     "x-forwarded-for": "192.0.2.1",
-    "accept-encoding": "gzip",
-    "connection": "close"
+    "accept-encoding": "gzip"
   },
   "url": "/echo/01BM82CJ9K1WK6EFJX8C1R4YH7/foo%20%25%20bar%20&%20baz%20=%20raz",
   "body": ""
@@ -91,8 +98,7 @@ func TestRelay(t *testing.T) {
     "host": "example.com",
     "content-length": "14",
     "x-forwarded-for": "192.0.2.1",
-    "accept-encoding": "gzip",
-    "connection": "close"
+    "accept-encoding": "gzip"
   },
   "url": "/echo/something",
   "body": "Some body here"
@@ -126,6 +132,92 @@ func TestRelay(t *testing.T) {
 		assertString(t, "Hello World", res.Body.String())
 	})
 
+	t.Run("crash retry", func(t *testing.T) {
+		newHandler(t)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/crash-once", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "Hello World", res.Body.String())
+	})
+
+	t.Run("crash no retry", func(t *testing.T) {
+		newHandler(t)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/crash-once", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 502, res.Code)
+		assertString(t, "", res.Body.String())
+	})
+
+	t.Run("crash loop", func(t *testing.T) {
+		newHandler(t)
+
+		// first
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/throw", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 502, res.Code)
+
+		// second exhausts the attempts
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/throw", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 503, res.Code)
+
+		// third
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/hello", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 503, res.Code)
+		assertString(t, "Application crashed repeatedly and is unavailable.\n", res.Body.String())
+	})
+
+	t.Run("crash loop cool-down", func(t *testing.T) {
+		newHandler(t)
+
+		for i := 0; i < 2; i++ {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/throw", nil)
+			h.ServeHTTP(res, req)
+		}
+
+		crashCooldown = 0
+		defer func() { crashCooldown = 30 * time.Second }()
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/hello", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "Hello World", res.Body.String())
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		newHandler(t)
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/hello", nil)
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Code)
+
+		assert.NoError(t, h.(*Proxy).Restart(), "restart")
+
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/restarts", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "1", res.Body.String())
+	})
+
 	t.Run("timeout", func(t *testing.T) {
 		newHandler(t)
 
@@ -147,6 +239,371 @@ func TestRelay(t *testing.T) {
 	})
 }
 
+func TestRelay_HealthCheck(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			HealthCheck: config.HealthCheck{
+				Path:     "/health",
+				Interval: config.Duration(100 * time.Millisecond),
+			},
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	start := time.Now()
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	defer h.(*Proxy).Shutdown()
+	assert.True(t, time.Since(start) >= 200*time.Millisecond, "should wait for the app to be healthy")
+
+	t.Run("healthy", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/restarts", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "0", res.Body.String())
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/sick", nil)
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Code)
+
+		// wait for restart
+		time.Sleep(time.Second)
+
+		res = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/restarts", nil)
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, 200, res.Code)
+		assertString(t, "1", res.Body.String())
+	})
+}
+
+func TestRelay_Workers(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			Workers:       2,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	defer h.(*Proxy).Shutdown()
+
+	t.Run("balancing", func(t *testing.T) {
+		ids := make(map[string]bool)
+
+		for i := 0; i < 4; i++ {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/worker", nil)
+			h.ServeHTTP(res, req)
+			assert.Equal(t, 200, res.Code)
+			ids[res.Body.String()] = true
+		}
+
+		assert.Equal(t, map[string]bool{"0": true, "1": true}, ids)
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/throw", nil)
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 502, res.Code)
+
+		for i := 0; i < 4; i++ {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/hello", nil)
+			h.ServeHTTP(res, req)
+			assert.Equal(t, 200, res.Code)
+		}
+	})
+}
+
+func TestRelay_Socket(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+			Socket:        true,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+
+	p := h.(*Proxy)
+	socket := p.workers[0].socket
+	assert.NotEmpty(t, socket, "socket")
+
+	t.Run("GET", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/hello", nil)
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "Hello World", res.Body.String())
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/crash-once", nil)
+		h.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Code)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		assert.NoError(t, p.Shutdown(), "shutdown")
+		_, err := os.Stat(socket)
+		assert.True(t, os.IsNotExist(err), "socket removed")
+	})
+}
+
+func TestRelay_Upgrade(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+	defer h.(*Proxy).Shutdown()
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	assert.NoError(t, err, "dial")
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "GET /echo HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+	assert.NoError(t, err, "write request")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	assert.NoError(t, err, "read response")
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "echo", res.Header.Get("Upgrade"))
+
+	_, err = io.WriteString(conn, "hello")
+	assert.NoError(t, err, "write")
+
+	b := make([]byte, 5)
+	_, err = io.ReadFull(br, b)
+	assert.NoError(t, err, "read")
+	assert.Equal(t, "hello", string(b))
+}
+
+func TestRelay_Shutdown(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:         2,
+			ListenTimeout:   2,
+			ShutdownTimeout: 2,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+
+	p := h.(*Proxy)
+	cmd := p.workers[0].cmd
+
+	start := time.Now()
+	assert.NoError(t, p.Shutdown(), "shutdown")
+
+	select {
+	case <-cmd.exited:
+	default:
+		t.Fatal("expected app to exit")
+	}
+
+	assert.True(t, time.Since(start) < time.Second, "should not wait for the timeout")
+}
+
+func TestRelay_Draining(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:         5,
+			ListenTimeout:   2,
+			ShutdownTimeout: 1,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+
+	p := h.(*Proxy)
+
+	// in-flight request holding up the drain
+	inflight := make(chan struct{})
+	go func() {
+		defer close(inflight)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/timeout", nil))
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- p.Shutdown()
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/hello", nil)
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 502, res.Code)
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "should not wait for the drain")
+
+	assert.NoError(t, <-shutdown, "shutdown")
+	<-inflight
+}
+
+func TestRelay_Restart(t *testing.T) {
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+		},
+	}
+
+	assert.NoError(t, c.Default(), "default")
+
+	h, err := New(c)
+	assert.NoError(t, err, "init")
+
+	p := h.(*Proxy)
+	defer p.Shutdown()
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 50)
+
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/hello", nil)
+			h.ServeHTTP(res, req)
+			codes <- res.Code
+		}()
+
+		if i == 10 {
+			assert.NoError(t, p.Restart(), "restart")
+		}
+	}
+
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		assert.Equal(t, 200, code)
+	}
+}
+
+func TestIsConnClosed(t *testing.T) {
+	reset := &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	timeout := &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}
+
+	assert.True(t, isConnClosed(io.EOF), "eof")
+	assert.True(t, isConnClosed(reset), "reset")
+	assert.True(t, isConnClosed(&url.Error{Op: "Get", Err: reset}), "wrapped reset")
+	assert.False(t, isConnClosed(timeout), "timeout")
+	assert.False(t, isConnClosed(context.Canceled), "canceled")
+	assert.False(t, isConnClosed(errors.New("boom")), "other")
+}
+
+func BenchmarkRelay(b *testing.B) {
+	skipCI(b)
+
+	os.Chdir("testdata/basic")
+	defer os.Chdir("../..")
+
+	c := &up.Config{
+		Proxy: config.Relay{
+			Timeout:       2,
+			ListenTimeout: 2,
+		},
+	}
+
+	assert.NoError(b, c.Default(), "default")
+
+	// bench runs requests against a proxy of its own,
+	// configuring its transport before any request.
+	bench := func(keepAlive bool) func(b *testing.B) {
+		return func(b *testing.B) {
+			h, err := New(c)
+			assert.NoError(b, err, "init")
+
+			p := h.(*Proxy)
+			defer p.Shutdown()
+
+			for _, w := range p.workers {
+				w.transport = newTransport(c, w.dial)
+				w.transport.DisableKeepAlives = !keepAlive
+			}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				res := httptest.NewRecorder()
+				req := httptest.NewRequest("GET", "/hello", nil)
+				h.ServeHTTP(res, req)
+				if res.Code != 200 {
+					b.Fatalf("expected 200, got %d", res.Code)
+				}
+			}
+		}
+	}
+
+	b.Run("keep-alive", bench(true))
+	b.Run("no keep-alive", bench(false))
+}
+
 func assertString(t testing.TB, want, got string) {
 	t.Helper()
 	if want != got {
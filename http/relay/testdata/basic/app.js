@@ -4,6 +4,10 @@ const qs = require('querystring');
 const port = process.env.PORT;
 
 let server;
+let ready = false;
+let sick = false;
+
+setTimeout(_ => ready = true, 200);
 
 const routes = {};
 
@@ -31,6 +35,29 @@ routes['/throw'] = (req, res) => {
   yaynode()
 };
 
+routes['/crash-once'] = (req, res) => {
+  if (process.env.UP_RESTARTS == '0') yaynode()
+  res.end('Hello World')
+};
+
+routes['/health'] = (req, res) => {
+  res.statusCode = ready && !sick ? 200 : 503
+  res.end()
+};
+
+routes['/sick'] = (req, res) => {
+  sick = true
+  res.end()
+};
+
+routes['/restarts'] = (req, res) => {
+  res.end(process.env.UP_RESTARTS)
+};
+
+routes['/worker'] = (req, res) => {
+  res.end(process.env.UP_WORKER_ID)
+};
+
 routes['/exit'] = (req, res) => {
   process.exit()
 };
@@ -45,4 +72,9 @@ server = http.createServer((req, res) => {
 
   res.setHeader('Content-Type', 'text/plain')
   res.end('Hello World')
-}).listen(port);
+}).listen(process.env.UP_SOCKET || port);
+
+server.on('upgrade', (req, socket) => {
+  socket.write('HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n')
+  socket.pipe(socket)
+});
@@ -0,0 +1,604 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/facebookgo/freeport"
+	"github.com/pkg/errors"
+	"github.com/tj/backoff"
+
+	"github.com/apex/up/internal/logs/writer"
+	"github.com/apex/up/internal/util"
+)
+
+// worker states.
+const (
+	starting int32 = iota
+	ready
+	crashed
+)
+
+// exitGracePeriod is the time given to the process to exit after a network
+// error on a reused connection, before the error is considered to be stale.
+var exitGracePeriod = 250 * time.Millisecond
+
+// crashCooldown is the time after which a crash-looping
+// app is restarted again, with the backoff reset.
+var crashCooldown = 30 * time.Second
+
+// staleConnError is a connection reset or EOF on a reused keep-alive
+// connection before the app responded, while the process is still running.
+type staleConnError struct {
+	error
+}
+
+// worker runs and monitors a single child process of the app.
+type worker struct {
+	// unhealthy is the time in nanoseconds the app was found unhealthy,
+	// zero once it has recovered, first for alignment as it is used atomically.
+	unhealthy int64
+
+	// id of the worker, exposed to the app as UP_WORKER_ID.
+	id int
+
+	// proxy the worker belongs to.
+	proxy *Proxy
+
+	// log context of the worker.
+	log log.Interface
+
+	// transport used for requests to the app.
+	transport *http.Transport
+
+	// health is the client used for health checks.
+	health *http.Client
+
+	// socket is the unix socket path the app listens on, if enabled.
+	socket string
+
+	// stdout is the log writer for structured logging output.
+	stdout *writer.Writer
+
+	// stderr is the log writer for structured logging output.
+	stderr *writer.Writer
+
+	// active is the number of in-flight requests.
+	active int32
+
+	// state of the worker.
+	state int32
+
+	mu sync.Mutex
+
+	// control serializes starting and stopping the process, so
+	// that mu may be released while the process drains.
+	control sync.Mutex
+
+	// crash serializes restarts after crashes, so that the lock
+	// is not held while waiting for the backoff delay.
+	crash sync.Mutex
+
+	// shutdown is true once the worker is shut down.
+	shutdown bool
+
+	// crashedAt is the time the worker gave up restarting the app.
+	crashedAt time.Time
+
+	// restarts is the restart count.
+	restarts int
+
+	// backoff used to pace restarts of a crashing app.
+	backoff *backoff.Backoff
+
+	// url is the active application url.
+	url *url.URL
+
+	// cmd is the current child process of the app.
+	cmd *process
+}
+
+// rank returns the worker's rank for balancing, where lower is preferred.
+func (w *worker) rank() int {
+	active := int(atomic.LoadInt32(&w.active))
+
+	switch atomic.LoadInt32(&w.state) {
+	case ready:
+		return active
+	case starting:
+		return 1<<20 + active
+	default:
+		return 1 << 30
+	}
+}
+
+// Start the server.
+func (w *worker) Start() error {
+	w.control.Lock()
+	defer w.control.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.shutdown = false
+	return w.start()
+}
+
+// start the server, the caller must hold the lock.
+func (w *worker) start() error {
+	atomic.StoreInt32(&w.state, starting)
+
+	if err := w.startServer(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	timeout := time.Duration(w.proxy.config.Proxy.ListenTimeout) * time.Second
+
+	if err := w.waitForListen(timeout); err != nil {
+		return err
+	}
+
+	w.log.WithField("duration", util.MillisecondsSince(start)).Info("app listening")
+
+	if !w.proxy.config.Proxy.HealthCheck.Enabled() {
+		atomic.StoreInt32(&w.state, ready)
+		return nil
+	}
+
+	w.log.WithField("path", w.proxy.config.Proxy.HealthCheck.Path).Info("waiting for app to be healthy")
+
+	if err := w.waitForHealthy(w.url, timeout-time.Since(start)); err != nil {
+		return errors.Wrapf(err, "waiting for %s to be healthy", w.url.String())
+	}
+
+	w.log.WithField("duration", util.MillisecondsSince(start)).Info("app healthy")
+	atomic.StoreInt32(&w.state, ready)
+	go w.monitor(w.cmd, w.url)
+
+	return nil
+}
+
+// waitForListen blocks until the app is listening with timeout.
+func (w *worker) waitForListen(timeout time.Duration) error {
+	if w.socket != "" {
+		w.log.Info("waiting for app to listen on UP_SOCKET")
+
+		if err := util.WaitForSocket(w.socket, timeout); err != nil {
+			return errors.Wrapf(err, "waiting for %s to be in listening state", w.socket)
+		}
+
+		return nil
+	}
+
+	w.log.Info("waiting for app to listen on PORT")
+
+	if err := util.WaitForListen(w.url, timeout); err != nil {
+		return errors.Wrapf(err, "waiting for %s to be in listening state", w.url.String())
+	}
+
+	return nil
+}
+
+// dial connects to the app, using the unix socket when enabled.
+func (w *worker) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if w.socket != "" {
+		return dialer.DialContext(ctx, "unix", w.socket)
+	}
+
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// Restart the server.
+func (w *worker) Restart() error {
+	w.control.Lock()
+	defer w.control.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.backoff.Reset()
+	return w.restart()
+}
+
+// restart the server, the caller must hold the control lock and the lock.
+func (w *worker) restart() error {
+	w.log.Warn("restarting")
+	w.restarts++
+
+	w.stop()
+
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.log.WithField("restarts", w.restarts).Warn("restarted")
+	return nil
+}
+
+// Shutdown the server gracefully.
+func (w *worker) Shutdown() error {
+	w.control.Lock()
+	defer w.control.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.shutdown = true
+	w.stop()
+	return nil
+}
+
+// stop the current process, the caller must hold the control lock and
+// the lock. The process is marked as draining so that no further requests
+// are sent to it, and the lock is released while waiting for it to exit.
+func (w *worker) stop() {
+	cmd := w.cmd
+	if cmd == nil || cmd.draining {
+		return
+	}
+
+	cmd.draining = true
+	atomic.StoreInt32(&w.state, starting)
+	w.log.Info("stopping app")
+
+	timeout := time.Duration(w.proxy.config.Proxy.ShutdownTimeout) * time.Second
+	w.mu.Unlock()
+	err := cmd.Stop(timeout)
+	w.mu.Lock()
+
+	// write any output pending in the log writers
+	w.stdout.Flush()
+	w.stderr.Flush()
+
+	// idle connections to the previous process are unusable
+	w.transport.CloseIdleConnections()
+
+	if w.socket != "" {
+		os.Remove(w.socket)
+	}
+
+	if err != nil {
+		w.log.WithError(err).Warn("killed app")
+		return
+	}
+
+	w.log.Info("app stopped")
+}
+
+// restartAfterCrash restarts the server after `cmd` crashed, pacing
+// restarts with the configured backoff. Nil is returned when another
+// request has already restarted the process, and errCrashLoop when
+// the attempts are exhausted.
+func (w *worker) restartAfterCrash(cmd *process) error {
+	w.crash.Lock()
+	defer w.crash.Unlock()
+
+	w.mu.Lock()
+
+	if atomic.LoadInt32(&w.state) == crashed {
+		w.mu.Unlock()
+		return errCrashLoop
+	}
+
+	if w.cmd != cmd || w.shutdown {
+		w.mu.Unlock()
+		return nil
+	}
+
+	// the app is not crash-looping if it served successfully
+	if cmd.Served() {
+		w.backoff.Reset()
+	}
+
+	if int(w.backoff.Attempt()) >= w.proxy.config.Proxy.Backoff.Attempts {
+		w.log.WithField("restarts", w.restarts).Error("app is crash-looping, giving up")
+		w.crashedAt = time.Now()
+		atomic.StoreInt32(&w.state, crashed)
+		w.mu.Unlock()
+		return errCrashLoop
+	}
+
+	d := w.backoff.Duration()
+	w.mu.Unlock()
+
+	w.log.WithField("delay", util.Milliseconds(d)).Warn("waiting to restart")
+	time.Sleep(d)
+
+	w.control.Lock()
+	defer w.control.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// restarted or shut down while waiting
+	if w.cmd != cmd || w.shutdown {
+		return nil
+	}
+
+	return w.restart()
+}
+
+// recover restarts the app with the backoff reset when it is
+// crash-looping and crashCooldown has elapsed since giving up,
+// otherwise errCrashLoop is returned.
+func (w *worker) recover() error {
+	if atomic.LoadInt32(&w.state) != crashed {
+		return nil
+	}
+
+	w.control.Lock()
+	defer w.control.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if atomic.LoadInt32(&w.state) != crashed {
+		return nil
+	}
+
+	if w.shutdown || time.Since(w.crashedAt) < crashCooldown {
+		return errCrashLoop
+	}
+
+	w.log.Warn("restarting crash-looping app after cool-down")
+	w.backoff.Reset()
+
+	if err := w.restart(); err != nil {
+		w.log.WithError(err).Error("restarting")
+		w.crashedAt = time.Now()
+		atomic.StoreInt32(&w.state, crashed)
+		return errCrashLoop
+	}
+
+	return nil
+}
+
+// RoundTrip implementation.
+func (w *worker) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := w.log.WithField("id", r.Header.Get("X-Request-Id"))
+
+	cmd, res, err := w.send(r)
+
+	// crash loop or shut down
+	if err == errCrashLoop || err == errShutdown {
+		return nil, err
+	}
+
+	// client went away
+	if err != nil && r.Context().Err() != nil {
+		ctx.WithError(err).Warn("request canceled")
+		return nil, err
+	}
+
+	// stale keep-alive connection
+	if e, ok := err.(*staleConnError); ok {
+		ctx.WithError(e.error).Warn("request stale connection error")
+		return nil, e.error
+	}
+
+	// timeout error
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		ctx.WithError(err).Warn("request timeout")
+		return res, err
+	}
+
+	// temporary error
+	if e, ok := err.(net.Error); ok && e.Temporary() {
+		ctx.WithError(err).Warn("request temporary error")
+		return res, err
+	}
+
+	// network error
+	if err != nil {
+		ctx.WithError(err).Error("request network error")
+
+		if err := w.restartAfterCrash(cmd); err != nil {
+			ctx.WithError(err).Error("restarting")
+			return nil, err
+		}
+
+		if !isRetryable(r) {
+			return res, err
+		}
+
+		ctx.Warn("retrying request")
+		return w.retry(r)
+	}
+
+	return res, err
+}
+
+// send the request to the current process, tracking
+// it as in-flight until the response body is closed.
+func (w *worker) send(r *http.Request) (*process, *http.Response, error) {
+	if err := w.recover(); err != nil {
+		return nil, nil, err
+	}
+
+	cmd, host, err := w.acquire()
+	if err != nil {
+		return cmd, nil, err
+	}
+
+	var reused, responded bool
+	r = r.WithContext(httptrace.WithClientTrace(r.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			responded = true
+		},
+	}))
+
+	u := *r.URL
+	u.Host = host
+	r.URL = &u
+
+	done := func() {
+		atomic.AddInt32(&w.active, -1)
+		cmd.inflight.Done()
+	}
+
+	res, err := w.transport.RoundTrip(r)
+	if err != nil {
+		done()
+
+		// the app may have closed an idle connection, which
+		// is not a crash unless the process has exited
+		if reused && !responded && isConnClosed(err) && !cmd.ExitedWithin(exitGracePeriod) {
+			return cmd, nil, &staleConnError{err}
+		}
+
+		return cmd, nil, err
+	}
+
+	cmd.serve()
+
+	// tunnels may remain open indefinitely, so they are
+	// not waited upon when the process is stopped
+	if rwc, ok := res.Body.(io.ReadWriteCloser); ok && res.StatusCode == http.StatusSwitchingProtocols {
+		cmd.inflight.Done()
+		res.Body = &tunnel{ReadWriteCloser: rwc, done: func() {
+			atomic.AddInt32(&w.active, -1)
+		}}
+		return cmd, res, nil
+	}
+
+	res.Body = &body{ReadCloser: res.Body, done: done}
+	return cmd, res, nil
+}
+
+// acquire returns the current process and its host, tracking a request
+// as in-flight. When the process is draining, the restart in progress is
+// waited upon, and an error is returned if the process was not replaced.
+func (w *worker) acquire() (*process, string, error) {
+	for i := 0; ; i++ {
+		w.mu.Lock()
+		cmd, host := w.cmd, w.url.Host
+
+		switch {
+		case w.shutdown:
+			w.mu.Unlock()
+			return cmd, "", errShutdown
+		case !cmd.draining:
+			cmd.inflight.Add(1)
+			atomic.AddInt32(&w.active, 1)
+			w.mu.Unlock()
+			return cmd, host, nil
+		case i > 0:
+			w.mu.Unlock()
+			return cmd, "", errors.New("app is not running")
+		}
+
+		w.mu.Unlock()
+
+		// the process is replaced before the control lock is released
+		w.control.Lock()
+		w.control.Unlock()
+	}
+}
+
+// isConnClosed returns true when err is the connection being reset or
+// closed by the app, excluding timeouts and cancellations.
+func isConnClosed(err error) bool {
+	for err != nil {
+		if e, ok := err.(net.Error); ok && e.Timeout() {
+			return false
+		}
+
+		switch err {
+		case io.EOF, io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.EPIPE:
+			return true
+		case context.Canceled, context.DeadlineExceeded:
+			return false
+		}
+
+		switch e := err.(type) {
+		case *net.OpError:
+			err = e.Err
+		case *os.SyscallError:
+			err = e.Err
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// retry the request against the restarted server.
+func (w *worker) retry(r *http.Request) (*http.Response, error) {
+	cmd, res, err := w.send(r)
+	if err != nil && err != errCrashLoop && err != errShutdown {
+		if err := w.restartAfterCrash(cmd); err != nil {
+			w.log.WithError(err).Error("restarting")
+			return nil, err
+		}
+	}
+
+	return res, err
+}
+
+// environment returns the server env variables.
+func (w *worker) environment() []string {
+	vars := []string{
+		env("PORT", w.url.Port()),
+		env("UP_RESTARTS", w.restarts),
+		env("UP_WORKER_ID", w.id),
+	}
+
+	if w.socket != "" {
+		vars = append(vars, env("UP_SOCKET", w.socket))
+	}
+
+	return vars
+}
+
+// startServer the server on a free port.
+func (w *worker) startServer() error {
+	port, err := freeport.Get()
+	if err != nil {
+		return errors.Wrap(err, "getting free port")
+	}
+
+	target, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", port))
+	if err != nil {
+		return errors.Wrap(err, "parsing url")
+	}
+
+	command := w.proxy.config.Proxy.Command
+
+	w.url = target
+
+	// remove a socket left behind by a crashed app
+	if w.socket != "" {
+		os.Remove(w.socket)
+	}
+
+	w.log.WithField("command", command).WithField("PORT", port).Info("starting app")
+	w.cmd = newProcess(w.command(command, w.environment()))
+
+	if err := w.cmd.Start(); err != nil {
+		return errors.Wrap(err, "running command")
+	}
+
+	w.log.Info("started app")
+	return nil
+}
+
+// command returns the command for spawning a server.
+func (w *worker) command(s string, env []string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", s)
+	cmd.Stdout = w.stdout
+	cmd.Stderr = w.stderr
+	cmd.Env = append(os.Environ(), append(env, "PATH=node_modules/.bin:"+os.Getenv("PATH"))...)
+	return cmd
+}
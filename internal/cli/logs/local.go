@@ -0,0 +1,109 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	jsonlog "github.com/apex/log/handlers/json"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs/access"
+	"github.com/apex/up/internal/logs/filter"
+	"github.com/apex/up/internal/logs/text"
+	"github.com/apex/up/internal/util"
+)
+
+// stdout returns a handler writing logs to stdout.
+func stdout(expand, outputJSON, color bool) log.Handler {
+	if outputJSON {
+		return jsonlog.New(os.Stdout)
+	}
+
+	return text.New(os.Stdout).WithExpandedFields(expand).WithColors(color)
+}
+
+// showFile outputs the logs in path matching query to handler.
+func showFile(path, query string, handler log.Handler) error {
+	f, err := filter.New(query)
+	if err != nil {
+		return errors.Wrap(err, "parsing query")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "opening")
+	}
+	defer file.Close()
+
+	return scan(file, func(line string, e *log.Entry) error {
+		if !f.Match(e) {
+			return nil
+		}
+
+		return handler.HandleLog(e)
+	})
+}
+
+// filterLines writes the lines of r matching query to w.
+func filterLines(r io.Reader, w io.Writer, query string) error {
+	f, err := filter.New(query)
+	if err != nil {
+		return errors.Wrap(err, "parsing query")
+	}
+
+	return scan(r, func(line string, e *log.Entry) error {
+		if !f.Match(e) {
+			return nil
+		}
+
+		_, err := fmt.Fprintln(w, line)
+		return err
+	})
+}
+
+// scan calls fn with each non-empty line of r, as read,
+// and its entry parsed without surrounding whitespace.
+func scan(r io.Reader, fn func(line string, e *log.Entry) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64<<10), 1<<20)
+
+	for s.Scan() {
+		line := s.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			continue
+		}
+
+		if err := fn(line, parseLine(trimmed)); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(s.Err(), "reading")
+}
+
+// parseLine returns the entry for a line of JSON, an access
+// log line, or an info level entry for plain text.
+func parseLine(s string) *log.Entry {
+	if util.IsJSONLog(s) {
+		var e log.Entry
+		if err := json.Unmarshal([]byte(s), &e); err == nil {
+			return &e
+		}
+	}
+
+	if e, ok := access.Parse(s); ok {
+		return e
+	}
+
+	return &log.Entry{
+		Level:   log.InfoLevel,
+		Message: s,
+	}
+}
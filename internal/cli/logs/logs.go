@@ -3,6 +3,8 @@ package logs
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -11,8 +13,11 @@ import (
 
 	"github.com/apex/up"
 	"github.com/apex/up/internal/cli/root"
+	"github.com/apex/up/internal/logs/parser"
 	"github.com/apex/up/internal/stats"
 	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/validate"
+	"github.com/apex/up/platform/aws/regions"
 )
 
 func init() {
@@ -34,55 +39,298 @@ func init() {
 	cmd.Example(`up logs 'user.email = "*@apex.sh"'`, "Show emails ending with @apex.sh.")
 	cmd.Example(`up logs 'user.email = "tj@*"'`, "Show emails starting with tj@.")
 	cmd.Example(`up logs 'method in ("POST", "PUT") ip = "207.*" status = 200 duration >= 50'`, "Show logs with a more complex query.")
+	cmd.Example(`up logs 'path matches /^\/users\/[0-9]+$/'`, "Show logs with paths matching a regular expression.")
+	cmd.Example(`up logs 'message icontains "login"'`, "Show logs with messages containing login, ignoring case.")
+	cmd.Example(`up logs 'status >= 500 | stats count() by path | sort count desc | limit 10'`, "Show the paths with the most 5xx responses.")
+	cmd.Example(`up logs 'status >= 400 | fields id, status, path'`, "Show a table of 4xx and 5xx responses.")
+	cmd.Example(`up logs @slow`, "Show logs matching the query saved as slow in up.json.")
+	cmd.Example(`up logs --list-saved`, "List the queries saved in up.json.")
+	cmd.Example(`up logs -f --stage staging,production`, "Show live logs of the staging and production stages.")
+	cmd.Example(`up logs --region us-east-1,eu-west-1 error`, "Show error logs of several regions, merged by time.")
+	cmd.Example(`up logs -S 'yesterday 14:00' -U 'yesterday 15:30' error`, "Show error logs of a time window.")
+	cmd.Example(`up logs -S 2018-01-15T14:00:00Z -U 2018-01-15T15:00:00Z -o incident.ndjson.gz`, "Export the logs of a time window to a gzipped file.")
+	cmd.Example(`up logs -S 2h -o slow.csv --columns timestamp,path,duration 'duration > 1s'`, "Export slow responses to a CSV file.")
+	cmd.Example(`up logs --request 8ff53267-c33a-11e7-9685-15d48d102ae9`, "Show the logs of a request, with its duration and memory used.")
+	cmd.Example(`up logs -f --group`, "Show live logs grouped by request.")
+	cmd.Example(`up logs --template '{{.Timestamp}} {{.Fields.status}} {{.Fields.path}}'`, "Show logs formatted with a template.")
+	cmd.Example(`up logs --columns timestamp,status,duration,path 'status >= 400'`, "Show 4xx and 5xx responses as columns.")
+	cmd.Example(`up logs -f --color=always | less -R`, "Page live logs in color.")
+	cmd.Example(`up logs --json error`, "Show error logs as JSON on a terminal.")
 	cmd.Example(`up logs error | jq`, "Pipe JSON error logs to the jq tool.")
+	cmd.Example(`up logs --file app.log 'duration > 1s'`, "Show slow responses from a local log file.")
+	cmd.Example(`cat app.log | up logs --stdin 'status >= 500'`, "Filter JSON logs piped from stdin.")
+	cmd.Example(`up logs -f | up logs --stdin 'status >= 500'`, "Show 5xx responses of the live logs.")
 
-	query := cmd.Arg("query", "Query pattern for filtering logs.").String()
-	follow := cmd.Flag("follow", "Follow or tail the live logs.").Short('f').Bool()
-	since := cmd.Flag("since", "Show logs since duration (30s, 5m, 2h, 1h30m, 3d, 1M).").Short('S').Default("1d").String()
-	expand := cmd.Flag("expand", "Show expanded logs.").Short('e').Bool()
+	show(cmd)
+}
+
+// show logs.
+func show(c *kingpin.Cmd) {
+	query := c.Arg("query", "Query pattern for filtering logs.").String()
+	follow := c.Flag("follow", "Follow or tail the live logs.").Short('f').Bool()
+	since := c.Flag("since", "Show logs since duration (30s, 5m, 2h, 1h30m, 3d, 1M) or time (2018-01-15T14:00:00Z, yesterday 14:00).").Short('S').Default("1d").String()
+	until := c.Flag("until", "Show logs until duration or time, as with --since.").Short('U').String()
+	expand := c.Flag("expand", "Show expanded logs.").Short('e').Bool()
+	file := c.Flag("file", "Show logs from a local file instead of the platform.").String()
+	stdin := c.Flag("stdin", "Filter JSON logs from stdin, writing matching lines untouched.").Bool()
+	stageNames := c.Flag("stage", "Show logs of the given stages, comma-separated.").Short('s').Strings()
+	regionNames := c.Flag("region", "Show logs of the given regions, comma-separated.").Short('r').Strings()
+	saved := c.Flag("saved", "Use the named query saved in up.json.").String()
+	list := c.Flag("list-saved", "List the queries saved in up.json.").Bool()
+	cursor := c.Flag("cursor", "Resume logs from the cursor output when tailing fails.").String()
+	outputPath := c.Flag("output", "Write logs to a .ndjson, .csv or .gz file.").Short('o').String()
+	columns := c.Flag("columns", "Columns to output such as timestamp,status,path, comma-separated.").Strings()
+	color := c.Flag("color", "Colorize output: auto, always or never.").Default("auto").Enum("auto", "always", "never")
+	forceJSON := c.Flag("json", "Output JSON, even on a terminal.").Bool()
+	template := c.Flag("template", "Format each log with a Go template.").String()
+	request := c.Flag("request", "Show the logs of a request by id, including its Lambda invocation.").String()
+	group := c.Flag("group", "Show the logs of each request as a block.").Short('g').Bool()
+
+	c.Action(func(ctx *kingpin.ParseContext) (err error) {
+		// the config is only read when required, as
+		// filtering a file or stdin does not require one
+		var config *up.Config
+		var project *up.Project
+		initialize := func() (*up.Config, *up.Project, error) {
+			if config == nil {
+				c, p, err := root.Init()
+				if err != nil {
+					return nil, nil, errors.Wrap(err, "initializing")
+				}
+				config, project = c, p
+			}
+
+			return config, project, nil
+		}
+
+		if *list {
+			c, _, err := initialize()
+			if err != nil {
+				return err
+			}
+
+			return listSaved(c)
+		}
+
+		q := *query
+		tty := term.IsTerminal(os.Stdout.Fd())
+
+		tmpl := *template
+
+		if *forceJSON && tmpl != "" {
+			return errors.New("--json cannot be used with --template")
+		}
+
+		outputJSON := *forceJSON || (!tty && tmpl == "" && *color != "always")
+		colored := *color == "always" || (*color == "auto" && tty)
+
+		name := *saved
+		if strings.HasPrefix(q, "@") {
+			name = strings.TrimPrefix(q, "@")
+		} else if name != "" && q != "" {
+			return errors.New("a query cannot be used with --saved")
+		}
+
+		stages := split(*stageNames)
+
+		if name != "" {
+			c, _, err := initialize()
+			if err != nil {
+				return err
+			}
+
+			s, err := savedQuery(c, name)
+			if err != nil {
+				return err
+			}
+
+			q = s.Query
 
-	cmd.Action(func(_ *kingpin.ParseContext) error {
-		c, p, err := root.Init()
+			if s.Stage != "" && !isSet(ctx, "stage") {
+				stages = []string{s.Stage}
+			}
+
+			if s.Since != "" && !isSet(ctx, "since") {
+				*since = s.Since
+			}
+
+			if s.Expand && !isSet(ctx, "expand") {
+				*expand = true
+			}
+		}
+
+		if *stdin {
+			if *file != "" {
+				return errors.New("--file cannot be used with --stdin")
+			}
+
+			stats.Track("Filter Logs", map[string]interface{}{
+				"query_length": len(q),
+				"saved":        name != "",
+			})
+
+			return filterLines(os.Stdin, os.Stdout, q)
+		}
+
+		if *follow && *until != "" {
+			return errors.New("--until cannot be used when following logs")
+		}
+
+		if *follow && *request != "" {
+			return errors.New("--request cannot be used when following logs")
+		}
+
+		handler, err := format(tmpl, split(*columns), outputJSON, colored)
 		if err != nil {
-			return errors.Wrap(err, "initializing")
+			return err
 		}
 
-		var s time.Duration
+		if *outputPath != "" {
+			h, done, e := output(*outputPath, split(*columns))
+			if e != nil {
+				return errors.Wrap(e, "--output")
+			}
+
+			defer func() {
+				if e := done(); e != nil && err == nil {
+					err = errors.Wrap(e, "closing --output")
+				}
+			}()
 
-		if *since != "" {
-			s, err = util.ParseDuration(*since)
+			handler = h
+		}
+
+		if *file != "" {
+			if *group || *request != "" {
+				return errors.New("--group and --request cannot be used with --file")
+			}
+
+			stats.Track("Logs", map[string]interface{}{
+				"query":        q != "",
+				"query_length": len(q),
+				"saved":        name != "",
+				"file":         true,
+				"expand":       *expand,
+				"output":       filepath.Ext(*outputPath),
+			})
+
+			if handler == nil {
+				handler = stdout(*expand, outputJSON, colored)
+			}
+
+			return showFile(*file, q, handler)
+		}
+
+		// report syntax errors before touching AWS
+		if _, err := parser.Parse(q); err != nil {
+			return errors.Wrap(err, "parsing query")
+		}
+
+		c, p, err := initialize()
+		if err != nil {
+			return err
+		}
+
+		for _, s := range stages {
+			if c.Stages.GetByName(s) == nil {
+				return errors.Errorf("stage %q does not exist", s)
+			}
+		}
+
+		regionIDs := c.Regions
+		if len(*regionNames) > 0 {
+			regionIDs = split(*regionNames)
+		}
+
+		if err := validate.Lists(regionIDs, regions.IDs); err != nil {
+			return errors.Wrap(err, "--region")
+		}
+
+		now := time.Now()
+		start := now
+
+		if *since != "" && !*follow {
+			start, err = util.ParseTime(*since, now)
 			if err != nil {
-				return errors.Wrap(err, "parsing --since duration")
+				return errors.Wrap(err, "parsing --since")
 			}
 		}
 
-		if *follow {
-			s = time.Duration(0)
+		if *cursor != "" {
+			start, err = util.ParseCursor(*cursor)
+			if err != nil {
+				return errors.Wrap(err, "parsing --cursor")
+			}
 		}
 
-		q := *query
+		var end time.Time
+
+		if *until != "" {
+			end, err = util.ParseTime(*until, now)
+			if err != nil {
+				return errors.Wrap(err, "parsing --until")
+			}
+
+			if !end.After(start) {
+				return errors.Errorf("--until %s must be after --since %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+			}
+		}
 
 		stats.Track("Logs", map[string]interface{}{
 			"query":        q != "",
 			"query_length": len(q),
+			"saved":        name != "",
 			"follow":       *follow,
-			"since":        s.Round(time.Second),
+			"since":        now.Sub(start).Round(time.Second),
+			"until":        !end.IsZero(),
 			"expand":       *expand,
+			"stages":       len(stages),
+			"regions":      len(regionIDs),
+			"output":       filepath.Ext(*outputPath),
+			"request":      *request != "",
+			"group":        *group,
+			"template":     tmpl != "",
+			"columns":      len(*columns),
+			"color":        *color,
+			"json":         *forceJSON,
 		})
 
 		logs := p.Logs(up.LogsConfig{
-			Region:     c.Regions[0],
-			Since:      time.Now().Add(-s),
+			Regions:    regionIDs,
+			Since:      start,
+			Until:      end,
 			Follow:     *follow,
 			Expand:     *expand,
 			Query:      q,
-			OutputJSON: !term.IsTerminal(os.Stdout.Fd()),
+			Stages:     stages,
+			Request:    *request,
+			Group:      *group,
+			OutputJSON: outputJSON,
+			NoColor:    !colored,
+			Handler:    handler,
 		})
 
 		if _, err := io.Copy(os.Stdout, logs); err != nil {
+			if e, ok := err.(*up.LogsError); ok {
+				return errors.Wrapf(e.Err, "reading logs, resume with --cursor %s", util.FormatCursor(e.Cursor))
+			}
+
 			return errors.Wrap(err, "writing logs")
 		}
 
 		return nil
 	})
 }
+
+// split returns the comma-separated values of a repeated flag.
+func split(values []string) (v []string) {
+	for _, s := range values {
+		for _, s := range strings.Split(s, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				v = append(v, s)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs/export"
+	"github.com/apex/up/internal/logs/text"
+)
+
+// format returns a handler writing logs to stdout formatted with the
+// template or columns, or nil for the default layout of neither.
+func format(tmpl string, columns []string, outputJSON, color bool) (log.Handler, error) {
+	switch {
+	case tmpl != "":
+		t, err := text.Template(tmpl)
+		if err != nil {
+			return nil, errors.Wrap(err, "--template")
+		}
+		return text.New(os.Stdout).WithTemplate(t), nil
+	case len(columns) > 0 && outputJSON:
+		return export.NewJSON(os.Stdout, columns), nil
+	case len(columns) > 0:
+		return text.New(os.Stdout).WithColumns(columns).WithColors(color), nil
+	default:
+		return nil, nil
+	}
+}
+
+// output returns a handler writing logs to the file at path, as CSV or
+// newline-delimited JSON depending on its extension, gzipped when it
+// ends with .gz. The returned function must be called to flush the file.
+func output(path string, columns []string) (log.Handler, func() error, error) {
+	name := path
+	gz := filepath.Ext(name) == ".gz"
+	if gz {
+		name = strings.TrimSuffix(name, ".gz")
+	}
+
+	csv := false
+
+	switch ext := filepath.Ext(name); ext {
+	case ".csv":
+		csv = true
+	case ".ndjson", ".jsonl", ".json", "", ".log":
+	default:
+		return nil, nil, errors.Errorf("unsupported file extension %q, use .ndjson, .csv or .gz", ext)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "creating")
+	}
+
+	var w io.Writer = f
+	done := f.Close
+
+	if gz {
+		z := gzip.NewWriter(f)
+		w = z
+		done = func() error {
+			if err := z.Close(); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		}
+	}
+
+	if csv {
+		if len(columns) == 0 {
+			columns = export.Columns
+		}
+		return export.NewCSV(w, columns), done, nil
+	}
+
+	return export.NewJSON(w, columns), done, nil
+}
@@ -0,0 +1,50 @@
+package logs
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tj/kingpin"
+
+	"github.com/apex/up"
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/util"
+)
+
+// savedQuery returns the query saved as name in up.json.
+func savedQuery(c *up.Config, name string) (*config.Query, error) {
+	q, ok := c.Logs.Queries[name]
+	if !ok {
+		return nil, errors.Errorf("saved query %q does not exist, see --list-saved", name)
+	}
+
+	return q, nil
+}
+
+// listSaved outputs the queries saved in up.json.
+func listSaved(c *up.Config) error {
+	defer util.Pad()()
+
+	var names []string
+	for name := range c.Logs.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		util.LogName("@"+name, "%s", c.Logs.Queries[name].Query)
+	}
+
+	return nil
+}
+
+// isSet returns true if flag name was specified.
+func isSet(ctx *kingpin.ParseContext, name string) bool {
+	for _, e := range ctx.Elements {
+		if f, ok := e.Clause.(*kingpin.FlagClause); ok && f.Model().Name == name {
+			return true
+		}
+	}
+
+	return false
+}
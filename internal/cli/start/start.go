@@ -12,8 +12,11 @@ import (
 	"github.com/tj/kingpin"
 
 	"github.com/apex/up/handler"
+	"github.com/apex/up/http/relay"
 	"github.com/apex/up/internal/cli/root"
+	"github.com/apex/up/internal/logs/filter"
 	"github.com/apex/up/internal/logs/text"
+	"github.com/apex/up/internal/signal"
 	"github.com/apex/up/internal/stats"
 )
 
@@ -24,14 +27,23 @@ func init() {
 	cmd.Example(`up start --address :5000`, "Start development server on port 5000.")
 	cmd.Example(`up start -c 'go run main.go'`, "Override proxy command.")
 	cmd.Example(`up start -oc 'gin --port $PORT'`, "Override proxy command and open in the browser.")
+	cmd.Example(`up start --watch`, "Start development server and restart the app on changes.")
+	cmd.Example(`up start --filter 'status >= 500'`, "Start development server and only show 5xx responses.")
 
 	stage := cmd.Flag("stage", "Target stage name.").Short('s').Default("development").String()
 	command := cmd.Flag("command", "Proxy command override").Short('c').String()
 	open := cmd.Flag("open", "Open endpoint in the browser.").Short('o').Bool()
 	addr := cmd.Flag("address", "Address for server.").Default("localhost:3000").String()
+	watch := cmd.Flag("watch", "Restart the app on changes.").Short('w').Bool()
+	query := cmd.Flag("filter", "Query pattern for filtering logs.").String()
 
 	cmd.Action(func(_ *kingpin.ParseContext) error {
-		log.SetHandler(text.New(os.Stdout))
+		f, err := filter.New(*query)
+		if err != nil {
+			return errors.Wrap(err, "parsing --filter query")
+		}
+
+		log.SetHandler(filter.NewHandler(f, text.New(os.Stdout)))
 
 		c, p, err := root.Init()
 		if err != nil {
@@ -45,6 +57,8 @@ func init() {
 		stats.Track("Start", map[string]interface{}{
 			"address":     *addr,
 			"has_command": *command != "",
+			"watch":       *watch,
+			"filter":      *query != "",
 		})
 
 		if err := p.Init(*stage); err != nil {
@@ -59,23 +73,44 @@ func init() {
 			c.Proxy.Command = s
 		}
 
-		h, err := handler.FromConfig(c)
+		app, err := handler.FromConfig(c)
 		if err != nil {
 			return errors.Wrap(err, "selecting handler")
 		}
 
-		h, err = handler.New(c, h)
+		// stop the app gracefully on exit
+		if p, ok := app.(*relay.Proxy); ok {
+			signal.Add(p.Shutdown)
+		}
+
+		h, err := handler.New(c, app)
 		if err != nil {
 			return errors.Wrap(err, "initializing handler")
 		}
 
+		server := handler.NewSwap(h)
+
+		if *watch || c.Proxy.Watch.Enable {
+			r := &reloader{
+				config:  c,
+				project: p,
+				stage:   *stage,
+				app:     app,
+				server:  server,
+			}
+
+			if err := r.Watch(); err != nil {
+				return errors.Wrap(err, "watching")
+			}
+		}
+
 		if *open {
 			_, port, _ := net.SplitHostPort(*addr)
 			browser.OpenURL(fmt.Sprintf("http://localhost:%s", port))
 		}
 
 		log.WithField("address", "http://"+*addr).Info("listening")
-		if err := http.ListenAndServe(*addr, h); err != nil {
+		if err := http.ListenAndServe(*addr, server); err != nil {
 			return errors.Wrap(err, "binding")
 		}
 
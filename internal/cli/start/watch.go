@@ -0,0 +1,173 @@
+package start
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+	"github.com/tj/go-archive"
+
+	"github.com/apex/up"
+	"github.com/apex/up/handler"
+	"github.com/apex/up/http/relay"
+	"github.com/apex/up/internal/errorpage"
+	"github.com/apex/up/internal/util"
+	"github.com/apex/up/internal/watch"
+	"github.com/apex/up/internal/zip"
+)
+
+// ignored paths in addition to those ignored by deploys.
+var ignored = []string{
+	"node_modules",
+}
+
+// reloader restarts the app and rebuilds the middleware on changes.
+type reloader struct {
+	config  *up.Config
+	project *up.Project
+	stage   string
+	app     http.Handler
+	server  *handler.Swap
+	watcher *watch.Watcher
+}
+
+// Watch for changes in the background.
+func (r *reloader) Watch() error {
+	c := r.config.Proxy.Watch
+
+	ignore, err := zip.Filter(append(append([]string{}, ignored...), c.Ignore...)...)
+	if err != nil {
+		return errors.Wrap(err, "ignore patterns")
+	}
+
+	w := &watch.Watcher{
+		Dir:      ".",
+		Ignore:   ignore,
+		Interval: time.Duration(c.Interval),
+		Debounce: time.Duration(c.Debounce),
+	}
+
+	if len(c.Paths) > 0 {
+		paths, err := archive.FilterPatterns(strings.NewReader(strings.Join(c.Paths, "\n")))
+		if err != nil {
+			return errors.Wrap(err, "paths patterns")
+		}
+		w.Paths = paths
+	}
+
+	changes, err := w.Start()
+	if err != nil {
+		return err
+	}
+
+	r.watcher = w
+
+	log.Info("watching for changes")
+
+	go func() {
+		for paths := range changes {
+			r.reload(paths)
+		}
+	}()
+
+	return nil
+}
+
+// reload after `paths` changed. The app is only restarted when
+// source files changed, not when only the configuration did.
+func (r *reloader) reload(paths []string) {
+	ctx := log.WithField("files", paths)
+	start := time.Now()
+
+	if r.hasSource(paths) {
+		if err := r.restart(); err != nil {
+			ctx.WithError(err).Error("reloading")
+			return
+		}
+	}
+
+	if err := r.reloadMiddleware(); err != nil {
+		ctx.WithError(err).Error("reloading")
+		return
+	}
+
+	ctx.WithField("duration", util.MillisecondsSince(start)).Info("reloaded")
+}
+
+// restart runs the build hook when enabled, then restarts the app.
+// Changes made while building are discarded, as the build output
+// would otherwise restart the app again.
+func (r *reloader) restart() error {
+	if c := r.config.Proxy.Watch; c.Build {
+		hook := c.Hook
+		if hook.IsEmpty() {
+			hook = r.config.Hooks.Build
+		}
+
+		err := r.project.RunCommands("build", hook)
+		r.watcher.Reset()
+		if err != nil {
+			return errors.Wrap(err, "build hook")
+		}
+	}
+
+	// static sites are served from disk
+	p, ok := r.app.(*relay.Proxy)
+	if !ok {
+		return nil
+	}
+
+	if err := p.Restart(); err != nil {
+		return errors.Wrap(err, "restarting")
+	}
+
+	return nil
+}
+
+// reloadMiddleware re-reads the configuration and rebuilds the
+// middleware, picking up changes to _headers, redirects, error
+// pages and injection without restarting the app.
+func (r *reloader) reloadMiddleware() error {
+	next, err := up.ReadConfig("up.json")
+	if err != nil {
+		return errors.Wrap(err, "reading config")
+	}
+
+	if err := next.Override(r.stage); err != nil {
+		return errors.Wrap(err, "overriding")
+	}
+
+	c := *r.config
+	c.Headers = next.Headers
+	c.Redirects = next.Redirects
+	c.ErrorPages = next.ErrorPages
+	c.Inject = next.Inject
+
+	h, err := handler.New(&c, r.app)
+	if err != nil {
+		return errors.Wrap(err, "initializing handler")
+	}
+
+	r.server.Set(h)
+	return nil
+}
+
+// hasSource returns true if any of the paths is not
+// configuration read by the middleware.
+func (r *reloader) hasSource(paths []string) bool {
+	dir := filepath.Clean(r.config.ErrorPages.Dir)
+
+	for _, path := range paths {
+		switch {
+		case path == "up.json", path == "_headers":
+		case filepath.Dir(path) == dir && errorpage.IsErrorPage(filepath.Base(path)):
+		default:
+			return true
+		}
+	}
+
+	return false
+}
@@ -86,7 +86,7 @@ func Load(dir string) (pages Pages, err error) {
 	}
 
 	for _, file := range files {
-		if !isErrorPage(file.Name()) {
+		if !IsErrorPage(file.Name()) {
 			continue
 		}
 
@@ -132,8 +132,8 @@ func Sort(pages Pages) {
 	})
 }
 
-// isErrorPage returns true if it looks like an error page.
-func isErrorPage(path string) bool {
+// IsErrorPage returns true if it looks like an error page.
+func IsErrorPage(path string) bool {
 	if filepath.Ext(path) != ".html" {
 		return false
 	}
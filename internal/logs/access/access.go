@@ -0,0 +1,167 @@
+// Package access provides formatting and parsing of
+// NCSA Common and Combined access log lines.
+package access
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// timeFormat is the NCSA timestamp format.
+const timeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// Line is an access log line.
+type Line struct {
+	// IP of the client.
+	IP string
+
+	// User authenticated, if any.
+	User string
+
+	// Time the request was received.
+	Time time.Time
+
+	// Method of the request.
+	Method string
+
+	// URI of the request, including the query string.
+	URI string
+
+	// Protocol of the request, such as "HTTP/1.1".
+	Protocol string
+
+	// Status of the response.
+	Status int
+
+	// Size of the response body.
+	Size int
+
+	// Referer of the request.
+	Referer string
+
+	// UserAgent of the request.
+	UserAgent string
+}
+
+// Common returns the line in the Common log format.
+func (l Line) Common() string {
+	size := "-"
+	if l.Size > 0 {
+		size = strconv.Itoa(l.Size)
+	}
+
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`,
+		dash(l.IP),
+		dash(l.User),
+		l.Time.Format(timeFormat),
+		l.Method,
+		l.URI,
+		l.Protocol,
+		l.Status,
+		size)
+}
+
+// Combined returns the line in the Combined log format.
+func (l Line) Combined() string {
+	return fmt.Sprintf(`%s "%s" "%s"`, l.Common(), quote(dash(l.Referer)), quote(dash(l.UserAgent)))
+}
+
+// quoting replacers.
+var (
+	quoter   = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	unquoter = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+)
+
+// line regexp.
+var line = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "(\S+) (\S+)(?: (\S+))?" (\d{3}) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?$`)
+
+// Parse parses a Common or Combined log line into a "response"
+// entry, with its level derived from the status code.
+func Parse(s string) (*log.Entry, bool) {
+	m := line.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, false
+	}
+
+	t, err := time.Parse(timeFormat, m[4])
+	if err != nil {
+		return nil, false
+	}
+
+	status, _ := strconv.Atoi(m[8])
+	size, _ := strconv.Atoi(m[9])
+
+	path, query := m[6], ""
+	if i := strings.IndexByte(path, '?'); i != -1 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	if p, err := url.PathUnescape(path); err == nil {
+		path = p
+	}
+
+	f := log.Fields{
+		"ip":     m[1],
+		"method": m[5],
+		"path":   path,
+		"query":  query,
+		"status": float64(status),
+		"size":   float64(size),
+	}
+
+	set(f, "user", m[3])
+	set(f, "referer", unquote(m[10]))
+	set(f, "user_agent", unquote(m[11]))
+
+	return &log.Entry{
+		Timestamp: t,
+		Level:     level(status),
+		Message:   "response",
+		Fields:    f,
+	}, true
+}
+
+// level returns the log level for a status code,
+// matching the levels of structured responses.
+func level(status int) log.Level {
+	switch {
+	case status >= 500:
+		return log.ErrorLevel
+	case status >= 400:
+		return log.WarnLevel
+	default:
+		return log.InfoLevel
+	}
+}
+
+// set field name to s unless it is empty.
+func set(f log.Fields, name, s string) {
+	if s != "" && s != "-" {
+		f[name] = s
+	}
+}
+
+// dash returns "-" for empty values.
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// quote escapes s for use in a quoted value.
+func quote(s string) string {
+	return quoter.Replace(s)
+}
+
+// unquote reverses quote.
+func unquote(s string) string {
+	return unquoter.Replace(s)
+}
@@ -0,0 +1,69 @@
+package access
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+var example = Line{
+	IP:        "192.0.2.1",
+	Time:      time.Date(2017, 10, 16, 13, 55, 36, 0, time.FixedZone("", -7*3600)),
+	Method:    "GET",
+	URI:       "/pets/tobi?page=2",
+	Protocol:  "HTTP/1.1",
+	Status:    200,
+	Size:      2326,
+	Referer:   "https://apex.sh/",
+	UserAgent: `curl "7.54"`,
+}
+
+func TestLine_Common(t *testing.T) {
+	assert.Equal(t, `192.0.2.1 - - [16/Oct/2017:13:55:36 -0700] "GET /pets/tobi?page=2 HTTP/1.1" 200 2326`, example.Common())
+}
+
+func TestLine_Combined(t *testing.T) {
+	assert.Equal(t, `192.0.2.1 - - [16/Oct/2017:13:55:36 -0700] "GET /pets/tobi?page=2 HTTP/1.1" 200 2326 "https://apex.sh/" "curl \"7.54\""`, example.Combined())
+}
+
+func TestParse(t *testing.T) {
+	t.Run("combined", func(t *testing.T) {
+		e, ok := Parse(example.Combined())
+		assert.True(t, ok)
+		assert.Equal(t, "response", e.Message)
+		assert.Equal(t, log.InfoLevel, e.Level)
+		assert.True(t, example.Time.Equal(e.Timestamp))
+		assert.Equal(t, log.Fields{
+			"ip":         "192.0.2.1",
+			"method":     "GET",
+			"path":       "/pets/tobi",
+			"query":      "page=2",
+			"status":     float64(200),
+			"size":       float64(2326),
+			"referer":    "https://apex.sh/",
+			"user_agent": `curl "7.54"`,
+		}, e.Fields)
+	})
+
+	t.Run("common", func(t *testing.T) {
+		e, ok := Parse(`10.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "POST /login HTTP/1.0" 503 -`)
+		assert.True(t, ok)
+		assert.Equal(t, log.ErrorLevel, e.Level)
+		assert.Equal(t, log.Fields{
+			"ip":     "10.0.0.1",
+			"method": "POST",
+			"path":   "/login",
+			"query":  "",
+			"status": float64(503),
+			"size":   float64(0),
+			"user":   "frank",
+		}, e.Fields)
+	})
+
+	t.Run("not access log", func(t *testing.T) {
+		_, ok := Parse(`Server listening on port 3000`)
+		assert.False(t, ok)
+	})
+}
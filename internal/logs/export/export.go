@@ -0,0 +1,132 @@
+// Package export provides handlers writing logs as newline-delimited
+// JSON or CSV, optionally selecting the columns written.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// Columns is the default columns of CSV output.
+var Columns = []string{"timestamp", "level", "message"}
+
+// Value returns the value of column name for entry e, which is the
+// timestamp, level or message of the entry, otherwise a field, where
+// dots select the members of nested fields such as "user.email".
+func Value(e *log.Entry, name string) interface{} {
+	switch name {
+	case "timestamp":
+		return e.Timestamp
+	case "level":
+		return e.Level.String()
+	case "message":
+		return e.Message
+	}
+
+	if v, ok := e.Fields[name]; ok {
+		return v
+	}
+
+	var v interface{} = map[string]interface{}(e.Fields)
+
+	for _, key := range strings.Split(name, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		v = m[key]
+	}
+
+	return v
+}
+
+// String returns the value v formatted as a string.
+func String(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// JSON handler writing entries as newline-delimited JSON.
+type JSON struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	columns []string
+}
+
+// NewJSON returns a handler writing to w, entries are written as-is
+// unless columns are specified, then only those values are written.
+func NewJSON(w io.Writer, columns []string) *JSON {
+	return &JSON{
+		enc:     json.NewEncoder(w),
+		columns: columns,
+	}
+}
+
+// HandleLog implements log.Handler.
+func (h *JSON) HandleLog(e *log.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.columns) == 0 {
+		return h.enc.Encode(e)
+	}
+
+	m := make(map[string]interface{}, len(h.columns))
+	for _, c := range h.columns {
+		m[c] = Value(e, c)
+	}
+
+	return h.enc.Encode(m)
+}
+
+// CSV handler writing entries as CSV records, with a header of the columns.
+type CSV struct {
+	mu      sync.Mutex
+	w       *csv.Writer
+	columns []string
+	header  bool
+}
+
+// NewCSV returns a handler writing columns to w.
+func NewCSV(w io.Writer, columns []string) *CSV {
+	return &CSV{
+		w:       csv.NewWriter(w),
+		columns: columns,
+	}
+}
+
+// HandleLog implements log.Handler.
+func (h *CSV) HandleLog(e *log.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.header {
+		h.w.Write(h.columns)
+		h.header = true
+	}
+
+	record := make([]string, len(h.columns))
+	for i, c := range h.columns {
+		record[i] = String(Value(e, c))
+	}
+
+	h.w.Write(record)
+	h.w.Flush()
+	return h.w.Error()
+}
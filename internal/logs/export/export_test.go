@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+var entry = &log.Entry{
+	Timestamp: time.Date(2018, 1, 15, 10, 30, 0, 0, time.UTC),
+	Level:     log.InfoLevel,
+	Message:   "response",
+	Fields: log.Fields{
+		"status": float64(200),
+		"path":   "/users",
+		"user": map[string]interface{}{
+			"email": "tj@apex.sh",
+		},
+	},
+}
+
+func TestValue(t *testing.T) {
+	assert.Equal(t, entry.Timestamp, Value(entry, "timestamp"))
+	assert.Equal(t, "info", Value(entry, "level"))
+	assert.Equal(t, "response", Value(entry, "message"))
+	assert.Equal(t, float64(200), Value(entry, "status"))
+	assert.Equal(t, "tj@apex.sh", Value(entry, "user.email"))
+	assert.Equal(t, nil, Value(entry, "user.name"))
+	assert.Equal(t, nil, Value(entry, "path.name"))
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("entries", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := NewJSON(&buf, nil)
+		assert.NoError(t, h.HandleLog(entry), "handle")
+		assert.Equal(t, `{"fields":{"path":"/users","status":200,"user":{"email":"tj@apex.sh"}},"level":"info","timestamp":"2018-01-15T10:30:00Z","message":"response"}`+"\n", buf.String())
+	})
+
+	t.Run("columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := NewJSON(&buf, []string{"timestamp", "status", "user.email"})
+		assert.NoError(t, h.HandleLog(entry), "handle")
+		assert.Equal(t, `{"status":200,"timestamp":"2018-01-15T10:30:00Z","user.email":"tj@apex.sh"}`+"\n", buf.String())
+	})
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewCSV(&buf, []string{"timestamp", "level", "message", "status", "user.email", "missing"})
+	assert.NoError(t, h.HandleLog(entry), "handle")
+	assert.NoError(t, h.HandleLog(entry), "handle")
+
+	s := "timestamp,level,message,status,user.email,missing\n"
+	s += "2018-01-15T10:30:00Z,info,response,200,tj@apex.sh,\n"
+	s += "2018-01-15T10:30:00Z,info,response,200,tj@apex.sh,\n"
+	assert.Equal(t, s, buf.String())
+}
@@ -0,0 +1,309 @@
+// Package filter provides local evaluation of log queries, matching
+// entries with the same semantics as the CloudWatch filter patterns
+// they compile to.
+package filter
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs/parser"
+	"github.com/apex/up/internal/logs/parser/ast"
+	"github.com/apex/up/internal/util"
+)
+
+// Filter matches log entries against a query.
+type Filter struct {
+	node ast.Node
+}
+
+// New filter from query, an empty query matches every entry.
+func New(query string) (*Filter, error) {
+	if strings.TrimSpace(query) == "" {
+		return &Filter{}, nil
+	}
+
+	n, err := parser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(n.(ast.Root).Stages) > 0 {
+		return nil, errors.New("pipeline stages such as stats or sort are only supported by up logs")
+	}
+
+	return &Filter{node: n}, nil
+}
+
+// Match returns true if the entry matches the query.
+func (f *Filter) Match(e *log.Entry) bool {
+	if f.node == nil {
+		return true
+	}
+
+	return Match(f.node, e)
+}
+
+// Handler forwards entries matching a filter to another handler.
+type Handler struct {
+	filter  *Filter
+	handler log.Handler
+}
+
+// NewHandler returns a handler forwarding entries matching f to h.
+func NewHandler(f *Filter, h log.Handler) *Handler {
+	return &Handler{
+		filter:  f,
+		handler: h,
+	}
+}
+
+// HandleLog implements log.Handler.
+func (h *Handler) HandleLog(e *log.Entry) error {
+	if !h.filter.Match(e) {
+		return nil
+	}
+
+	return h.handler.HandleLog(e)
+}
+
+// Match returns true if the entry matches node n.
+func Match(n ast.Node, e *log.Entry) bool {
+	switch n := n.(type) {
+	case ast.Root:
+		return n.Node == nil || Match(n.Node, e)
+	case ast.Expr:
+		return Match(n.Node, e)
+	case ast.Unary:
+		return !Match(n.Right, e)
+	case ast.Binary:
+		switch n.Op {
+		case ast.AND:
+			return Match(n.Left, e) && Match(n.Right, e)
+		case ast.OR:
+			return Match(n.Left, e) || Match(n.Right, e)
+		case ast.IN:
+			for _, v := range n.Right.(ast.Tuple) {
+				if compare(ast.EQ, n.Left, v, e) {
+					return true
+				}
+			}
+			return false
+		default:
+			return compare(n.Op, n.Left, n.Right, e)
+		}
+	case ast.String:
+		return wildcard(e.Message, string(n))
+	default:
+		v, ok := lookup(n, e)
+		return ok && truthy(v)
+	}
+}
+
+// compare the value of the left node to the right node's value.
+// Strings support equality with wildcards, optionally ignoring case,
+// and regular expressions, while numbers support all relational
+// operators, values of other types never match.
+func compare(op ast.Op, left, right ast.Node, e *log.Entry) bool {
+	v, ok := lookup(left, e)
+	if !ok {
+		return false
+	}
+
+	switch r := value(right).(type) {
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+
+		switch op {
+		case ast.EQ:
+			return wildcard(s, r)
+		case ast.NE:
+			return !wildcard(s, r)
+		case ast.IEQ:
+			return wildcard(strings.ToLower(s), strings.ToLower(r))
+		case ast.INE:
+			return !wildcard(strings.ToLower(s), strings.ToLower(r))
+		case ast.MATCH:
+			return compile(r).MatchString(s)
+		case ast.NOMATCH:
+			return !compile(r).MatchString(s)
+		default:
+			return false
+		}
+	case float64:
+		f := util.ToFloat(v)
+		if math.IsNaN(f) {
+			return false
+		}
+
+		switch op {
+		case ast.EQ, ast.IEQ:
+			return f == r
+		case ast.NE, ast.INE:
+			return f != r
+		case ast.GT:
+			return f > r
+		case ast.LT:
+			return f < r
+		case ast.GE:
+			return f >= r
+		case ast.LE:
+			return f <= r
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+}
+
+// value returns the literal value of a node on the
+// right-hand side of a comparison.
+func value(n ast.Node) interface{} {
+	switch n := n.(type) {
+	case ast.String:
+		return string(n)
+	case ast.Field:
+		return string(n)
+	case ast.Regexp:
+		return string(n)
+	case ast.Number:
+		return n.Float()
+	case ast.Contains:
+		if s, ok := n.Node.(ast.String); ok {
+			return "*" + string(s) + "*"
+		}
+		return value(n.Node)
+	default:
+		return nil
+	}
+}
+
+// lookup returns the value of a property or field node.
+func lookup(n ast.Node, e *log.Entry) (interface{}, bool) {
+	switch n := n.(type) {
+	case ast.Property:
+		switch n {
+		case "level":
+			return e.Level.String(), true
+		case "message":
+			return e.Message, true
+		case "timestamp":
+			return e.Timestamp.Format(time.RFC3339Nano), true
+		}
+	case ast.Field:
+		v, ok := e.Fields[string(n)]
+		return v, ok
+	case ast.Member:
+		v, ok := lookup(n.Left, e)
+		if !ok {
+			return nil, false
+		}
+		return member(v, string(n.Right.(ast.Literal)))
+	case ast.Subscript:
+		v, ok := lookup(n.Left, e)
+		if !ok {
+			return nil, false
+		}
+		return index(v, string(n.Right.(ast.Literal)))
+	}
+
+	return nil, false
+}
+
+// member returns the value of key in map v.
+func member(v interface{}, key string) (interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		v, ok := m[key]
+		return v, ok
+	case log.Fields:
+		v, ok := m[key]
+		return v, ok
+	default:
+		return nil, false
+	}
+}
+
+// index returns the value at index s in slice v.
+func index(v interface{}, s string) (interface{}, bool) {
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 || i >= len(l) {
+		return nil, false
+	}
+
+	return l[i], true
+}
+
+// truthy returns true if v is present and not false, zero or empty.
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	default:
+		if f := util.ToFloat(v); !math.IsNaN(f) {
+			return f != 0
+		}
+		return true
+	}
+}
+
+// patterns is a cache of compiled regular expressions.
+var patterns sync.Map
+
+// compile returns the compiled regular expression s,
+// which has been validated by the parser.
+func compile(s string) *regexp.Regexp {
+	if re, ok := patterns.Load(s); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	re := regexp.MustCompile(s)
+	patterns.Store(s, re)
+	return re
+}
+
+// wildcard returns true if s matches pattern, where "*" matches
+// any sequence of characters, and matching is case-sensitive.
+func wildcard(s, pattern string) bool {
+	parts := strings.Split(pattern, "*")
+
+	if len(parts) == 1 {
+		return s == pattern
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i == -1 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+
+	return strings.HasSuffix(s, last)
+}
@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+var entry = &log.Entry{
+	Level:     log.WarnLevel,
+	Message:   "response",
+	Timestamp: time.Date(2017, 10, 16, 12, 0, 0, 0, time.UTC),
+	Fields: log.Fields{
+		"stage":    "production",
+		"method":   "POST",
+		"path":     "/account/billing",
+		"ip":       "207.1.2.3",
+		"status":   float64(404),
+		"duration": 1500,
+		"size":     int64(2048),
+		"admin":    false,
+		"user": map[string]interface{}{
+			"name":  "Tobi",
+			"email": "tobi@apex.sh",
+		},
+		"cart": map[string]interface{}{
+			"products": []interface{}{
+				map[string]interface{}{"name": "ps4", "price": 15.99},
+			},
+		},
+	},
+}
+
+var cases = []struct {
+	Query string
+	Match bool
+}{
+	{``, true},
+	{`production`, true},
+	{`staging`, false},
+	{`warn`, true},
+	{`error or fatal`, false},
+	{`not info`, true},
+	{`!warn`, false},
+	{`level = "warn"`, true},
+	{`"response"`, true},
+	{`"resp*"`, true},
+	{`"request"`, false},
+	{`message = "*spon*"`, true},
+	{`method = "POST"`, true},
+	{`method = POST`, true},
+	{`method = "post"`, false},
+	{`method != "GET"`, true},
+	{`method in ("GET", "POST")`, true},
+	{`method not in ("GET", "POST")`, false},
+	{`path = /account/billing`, true},
+	{`ip = "207.*"`, true},
+	{`ip = "*.3"`, true},
+	{`ip = "10.*"`, false},
+	{`status = 404`, true},
+	{`status >= 400`, true},
+	{`status < 400`, false},
+	{`status = "404"`, false},
+	{`duration > 1s`, true},
+	{`duration > 1.5s`, false},
+	{`size >= 2kb`, true},
+	{`size > 2kb`, false},
+	{`user.name = "Tobi"`, true},
+	{`user.email contains "@apex"`, true},
+	{`user.email = "*@apex.sh"`, true},
+	{`user.missing = "x"`, false},
+	{`user.missing != "x"`, false},
+	{`cart.products[0].name = ps4`, true},
+	{`cart.products[0].price > 15`, true},
+	{`cart.products[1].price > 15`, false},
+	{`admin`, false},
+	{`not admin`, true},
+	{`path > "/a"`, false},
+	{`production warn (status = 500 or status = 404)`, true},
+	{`production and status = 500`, false},
+	{`not method = "GET" or status = 500`, true},
+	{`path matches /^\/account\/\w+$/`, true},
+	{`path =~ /billing$/`, true},
+	{`path =~ /^billing/`, false},
+	{`path !~ /^\/static/`, true},
+	{`user.email matches /(?i)TOBI@/`, true},
+	{`status matches /404/`, false},
+	{`user.missing !~ /x/`, false},
+	{`method ieq "post"`, true},
+	{`method ieq "p*"`, true},
+	{`method ine "post"`, false},
+	{`user.name icontains "OB"`, true},
+	{`status ieq 404`, true},
+}
+
+func TestMatch(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.Query, func(t *testing.T) {
+			f, err := New(c.Query)
+			assert.NoError(t, err, "parse")
+			assert.Equal(t, c.Match, f.Match(entry))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(`status >= 500 | stats count() by path`)
+	assert.EqualError(t, err, `pipeline stages such as stats or sort are only supported by up logs`)
+}
+
+func TestHandler(t *testing.T) {
+	f, err := New(`status >= 500`)
+	assert.NoError(t, err, "parse")
+
+	var entries []*log.Entry
+	h := NewHandler(f, log.HandlerFunc(func(e *log.Entry) error {
+		entries = append(entries, e)
+		return nil
+	}))
+
+	h.HandleLog(&log.Entry{Message: "ok", Fields: log.Fields{"status": 200}})
+	h.HandleLog(&log.Entry{Message: "boom", Fields: log.Fields{"status": 502}})
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "boom", entries[0].Message)
+}
@@ -0,0 +1,237 @@
+// Package group implements a log handler collecting the entries of each
+// request into a single block, including the START, END and REPORT lines
+// of the Lambda invocation which served it.
+//
+// Entries are grouped by their "id" field, while Lambda lines are matched
+// by the "lambda_id" field logged with requests. A request is complete
+// once its REPORT line is seen, or when no entry of the request has been
+// seen for a while relative to the latest entry's timestamp.
+package group
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/apex/up/internal/logs/lambda"
+	"github.com/apex/up/internal/util"
+)
+
+// Timeout is the duration after which a request without
+// a REPORT line is considered complete.
+var Timeout = time.Minute
+
+// Request is the entries of a single request.
+type Request struct {
+	// ID of the request, empty for entries which are not part of one.
+	ID string `json:"id,omitempty"`
+
+	// LambdaID is the request id of the Lambda invocation, when known.
+	LambdaID string `json:"lambda_id,omitempty"`
+
+	// Entries of the request in the order received.
+	Entries []*log.Entry `json:"entries"`
+
+	// Duration of the invocation in milliseconds,
+	// or of the response without a REPORT line.
+	Duration float64 `json:"duration,omitempty"`
+
+	// BilledDuration of the invocation in milliseconds.
+	BilledDuration float64 `json:"billed_duration,omitempty"`
+
+	// MemoryUsed by the invocation in megabytes.
+	MemoryUsed float64 `json:"memory_used,omitempty"`
+
+	// MemorySize of the function in megabytes.
+	MemorySize float64 `json:"memory_size,omitempty"`
+}
+
+// Timestamp returns the timestamp of the first entry.
+func (r *Request) Timestamp() time.Time {
+	return r.Entries[0].Timestamp
+}
+
+// last returns the timestamp of the last entry.
+func (r *Request) last() time.Time {
+	return r.Entries[len(r.Entries)-1].Timestamp
+}
+
+// report sets the metrics of the invocation from a REPORT entry.
+func (r *Request) report(e *log.Entry) {
+	r.Duration = util.ToFloat(e.Fields["duration"])
+	r.BilledDuration = util.ToFloat(e.Fields["billed_duration"])
+	r.MemoryUsed = util.ToFloat(e.Fields["memory_used"])
+	r.MemorySize = util.ToFloat(e.Fields["memory_size"])
+}
+
+// Handler implementation.
+type Handler struct {
+	mu       sync.Mutex
+	fn       func(*Request) error
+	requests map[string]*Request
+	aliases  map[string]string
+	latest   time.Time
+}
+
+// New handler calling fn with each request once complete. Entries
+// without a request id are passed immediately as a request without an ID.
+func New(fn func(*Request) error) *Handler {
+	return &Handler{
+		fn:       fn,
+		requests: make(map[string]*Request),
+		aliases:  make(map[string]string),
+	}
+}
+
+// HandleLog implements log.Handler.
+func (h *Handler) HandleLog(e *log.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id, _ := e.Fields["id"].(string)
+	lambdaID, _ := e.Fields["lambda_id"].(string)
+
+	if id == "" && lambdaID == "" {
+		return h.fn(&Request{Entries: []*log.Entry{e}})
+	}
+
+	key := h.key(id, lambdaID)
+
+	r, ok := h.requests[key]
+	if !ok {
+		r = &Request{ID: id}
+		h.requests[key] = r
+	}
+
+	r.Entries = append(r.Entries, e)
+
+	if lambdaID != "" {
+		r.LambdaID = lambdaID
+	}
+
+	// the response duration until the REPORT line is seen
+	if d, ok := e.Fields["duration"]; ok && e.Message == "response" && r.Duration == 0 {
+		r.Duration = util.ToFloat(d)
+	}
+
+	if e.Timestamp.After(h.latest) {
+		h.latest = e.Timestamp
+	}
+
+	if lambda.IsReport(e) {
+		r.report(e)
+		delete(h.requests, key)
+		delete(h.aliases, lambdaID)
+		if err := h.fn(r); err != nil {
+			return err
+		}
+	}
+
+	return h.expire(h.latest.Add(-Timeout))
+}
+
+// Flush passes the pending requests to fn, ordered by timestamp.
+func (h *Handler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.expire(time.Time{})
+}
+
+// LambdaIDs returns the Lambda request ids of the
+// requests which have not seen their REPORT line.
+func (h *Handler) LambdaIDs() (ids []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id := range h.aliases {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return
+}
+
+// key returns the key of the request an entry belongs to, Lambda lines
+// are keyed by their Lambda request id until a request line associates
+// it with the request id, merging the entries seen so far.
+func (h *Handler) key(id, lambdaID string) string {
+	if lambdaID == "" {
+		return id
+	}
+
+	if id == "" {
+		if key, ok := h.aliases[lambdaID]; ok {
+			return key
+		}
+		return lambdaID
+	}
+
+	if _, ok := h.aliases[lambdaID]; !ok {
+		h.aliases[lambdaID] = id
+
+		if r, ok := h.requests[lambdaID]; ok {
+			delete(h.requests, lambdaID)
+			r.ID = id
+
+			if existing, ok := h.requests[id]; ok {
+				r.Entries = append(r.Entries, existing.Entries...)
+				sort.SliceStable(r.Entries, func(i, j int) bool {
+					return r.Entries[i].Timestamp.Before(r.Entries[j].Timestamp)
+				})
+			}
+
+			h.requests[id] = r
+		}
+	}
+
+	return id
+}
+
+// expire passes the requests without entries since cutoff to fn, all
+// requests when cutoff is zero, ordered by the timestamp of their first entry.
+func (h *Handler) expire(cutoff time.Time) error {
+	var expired []*Request
+
+	for key, r := range h.requests {
+		if cutoff.IsZero() || r.last().Before(cutoff) {
+			expired = append(expired, r)
+			delete(h.requests, key)
+		}
+	}
+
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].Timestamp().Before(expired[j].Timestamp())
+	})
+
+	for _, r := range expired {
+		if err := h.fn(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JSON returns a function writing requests to w as JSON, one per line.
+func JSON(w io.Writer) func(*Request) error {
+	enc := json.NewEncoder(w)
+	return func(r *Request) error {
+		return enc.Encode(r)
+	}
+}
+
+// Entries returns a function passing the entries of requests to h.
+func Entries(h log.Handler) func(*Request) error {
+	return func(r *Request) error {
+		for _, e := range r.Entries {
+			if err := h.HandleLog(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
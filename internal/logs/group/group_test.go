@@ -0,0 +1,140 @@
+package group
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/tj/assert"
+)
+
+// entry returns an entry at second s.
+func entry(s int, msg string, fields log.Fields) *log.Entry {
+	return &log.Entry{
+		Timestamp: time.Date(2018, 1, 15, 10, 0, s, 0, time.UTC),
+		Level:     log.InfoLevel,
+		Message:   msg,
+		Fields:    fields,
+	}
+}
+
+// collect returns a handler appending requests to v.
+func collect(v *[]*Request) *Handler {
+	return New(func(r *Request) error {
+		*v = append(*v, r)
+		return nil
+	})
+}
+
+// messages returns the messages of the request's entries.
+func messages(r *Request) (v []string) {
+	for _, e := range r.Entries {
+		v = append(v, e.Message)
+	}
+	return
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("report", func(t *testing.T) {
+		var requests []*Request
+		h := collect(&requests)
+
+		h.HandleLog(entry(0, "start", log.Fields{"lambda_id": "l1"}))
+		h.HandleLog(entry(0, "request", log.Fields{"id": "a", "lambda_id": "l1"}))
+		h.HandleLog(entry(1, "start", log.Fields{"lambda_id": "l2"}))
+		h.HandleLog(entry(1, "request", log.Fields{"id": "b", "lambda_id": "l2"}))
+		h.HandleLog(entry(1, "user login", log.Fields{"id": "a"}))
+		h.HandleLog(entry(1, "initializing", nil))
+		h.HandleLog(entry(2, "response", log.Fields{"id": "a", "lambda_id": "l1"}))
+		h.HandleLog(entry(2, "end", log.Fields{"lambda_id": "l1"}))
+		assert.Equal(t, []string{"l1", "l2"}, h.LambdaIDs())
+
+		h.HandleLog(entry(2, "report", log.Fields{
+			"lambda_id":       "l1",
+			"duration":        1520.5,
+			"billed_duration": float64(1600),
+			"memory_size":     float64(512),
+			"memory_used":     float64(40),
+		}))
+
+		assert.Len(t, requests, 2)
+		assert.Equal(t, "", requests[0].ID)
+		assert.Equal(t, []string{"initializing"}, messages(requests[0]))
+
+		r := requests[1]
+		assert.Equal(t, "a", r.ID)
+		assert.Equal(t, "l1", r.LambdaID)
+		assert.Equal(t, []string{"start", "request", "user login", "response", "end", "report"}, messages(r))
+		assert.Equal(t, 1520.5, r.Duration)
+		assert.Equal(t, float64(1600), r.BilledDuration)
+		assert.Equal(t, float64(40), r.MemoryUsed)
+		assert.Equal(t, float64(512), r.MemorySize)
+		assert.Equal(t, []string{"l2"}, h.LambdaIDs())
+
+		assert.NoError(t, h.Flush(), "flush")
+		assert.Len(t, requests, 3)
+		assert.Equal(t, "b", requests[2].ID)
+		assert.Equal(t, []string{"start", "request"}, messages(requests[2]))
+	})
+
+	t.Run("merging", func(t *testing.T) {
+		var requests []*Request
+		h := collect(&requests)
+
+		h.HandleLog(entry(1, "user login", log.Fields{"id": "a"}))
+		h.HandleLog(entry(0, "start", log.Fields{"lambda_id": "l1"}))
+		h.HandleLog(entry(2, "response", log.Fields{"id": "a", "lambda_id": "l1"}))
+		assert.NoError(t, h.Flush(), "flush")
+
+		assert.Len(t, requests, 1)
+		assert.Equal(t, []string{"start", "user login", "response"}, messages(requests[0]))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var requests []*Request
+		h := collect(&requests)
+
+		h.HandleLog(entry(0, "request", log.Fields{"id": "a"}))
+		h.HandleLog(entry(1, "request", log.Fields{"id": "b"}))
+		assert.Len(t, requests, 0)
+
+		e := entry(0, "request", log.Fields{"id": "c"})
+		e.Timestamp = e.Timestamp.Add(Timeout + time.Second)
+		h.HandleLog(e)
+
+		assert.Len(t, requests, 1)
+		assert.Equal(t, "a", requests[0].ID)
+	})
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	fn := JSON(&buf)
+
+	err := fn(&Request{
+		ID:       "a",
+		Entries:  []*log.Entry{entry(0, "request", log.Fields{"id": "a"})},
+		Duration: 15,
+	})
+
+	assert.NoError(t, err, "encode")
+	assert.Equal(t, `{"id":"a","entries":[{"fields":{"id":"a"},"level":"info","timestamp":"2018-01-15T10:00:00Z","message":"request"}],"duration":15}`+"\n", buf.String())
+}
+
+func TestEntries(t *testing.T) {
+	h := memory.New()
+	fn := Entries(h)
+
+	err := fn(&Request{
+		ID: "a",
+		Entries: []*log.Entry{
+			entry(0, "request", log.Fields{"id": "a"}),
+			entry(1, "response", log.Fields{"id": "a"}),
+		},
+	})
+
+	assert.NoError(t, err, "handle")
+	assert.Len(t, h.Entries, 2)
+}
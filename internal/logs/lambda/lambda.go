@@ -0,0 +1,79 @@
+// Package lambda provides parsing of the START, END and
+// REPORT lines Lambda writes for each invocation.
+package lambda
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// line regexp.
+var line = regexp.MustCompile(`^(START|END|REPORT) RequestId: (\S+)`)
+
+// metric regexp, such as "Billed Duration: 100 ms".
+var metric = regexp.MustCompile(`([A-Z][A-Za-z ]+): ([0-9.]+) (?:ms|MB)`)
+
+// metrics mapping of REPORT metric names to fields.
+var metrics = map[string]string{
+	"Duration":        "duration",
+	"Billed Duration": "billed_duration",
+	"Init Duration":   "init_duration",
+	"Memory Size":     "memory_size",
+	"Max Memory Used": "memory_used",
+}
+
+// Parse parses a START, END or REPORT line into an entry with the
+// invocation's request id as the "lambda_id" field, and the
+// durations in milliseconds and memory in megabytes of REPORT lines.
+func Parse(s string) (*log.Entry, bool) {
+	s = strings.TrimSpace(s)
+
+	m := line.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+
+	f := log.Fields{
+		"lambda_id": m[2],
+	}
+
+	for _, m := range metric.FindAllStringSubmatch(s, -1) {
+		name, ok := metrics[strings.TrimSpace(m[1])]
+		if !ok {
+			continue
+		}
+
+		if v, err := strconv.ParseFloat(m[2], 64); err == nil {
+			f[name] = v
+		}
+	}
+
+	return &log.Entry{
+		Level:   log.InfoLevel,
+		Message: strings.ToLower(m[1]),
+		Fields:  f,
+	}, true
+}
+
+// Is returns true if the entry is a parsed START, END or REPORT line.
+func Is(e *log.Entry) bool {
+	if _, ok := e.Fields["id"]; ok {
+		return false
+	}
+
+	switch e.Message {
+	case "start", "end", "report":
+		_, ok := e.Fields["lambda_id"]
+		return ok
+	default:
+		return false
+	}
+}
+
+// IsReport returns true if the entry is a parsed REPORT line.
+func IsReport(e *log.Entry) bool {
+	return Is(e) && e.Message == "report"
+}
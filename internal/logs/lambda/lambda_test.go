@@ -0,0 +1,57 @@
+package lambda
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		e, ok := Parse("START RequestId: 3f5c0a4e-f9b4-11e7-a0f6-4b8f2a3a8c8d Version: $LATEST\n")
+		assert.True(t, ok)
+		assert.Equal(t, "start", e.Message)
+		assert.Equal(t, log.InfoLevel, e.Level)
+		assert.Equal(t, log.Fields{"lambda_id": "3f5c0a4e-f9b4-11e7-a0f6-4b8f2a3a8c8d"}, e.Fields)
+		assert.True(t, Is(e))
+		assert.False(t, IsReport(e))
+	})
+
+	t.Run("end", func(t *testing.T) {
+		e, ok := Parse("END RequestId: 3f5c0a4e-f9b4-11e7-a0f6-4b8f2a3a8c8d\n")
+		assert.True(t, ok)
+		assert.Equal(t, "end", e.Message)
+		assert.Equal(t, log.Fields{"lambda_id": "3f5c0a4e-f9b4-11e7-a0f6-4b8f2a3a8c8d"}, e.Fields)
+	})
+
+	t.Run("report", func(t *testing.T) {
+		e, ok := Parse("REPORT RequestId: 3f5c0a4e-f9b4-11e7-a0f6-4b8f2a3a8c8d\tDuration: 12.34 ms\tBilled Duration: 100 ms \tMemory Size: 512 MB\tMax Memory Used: 40 MB\t\n")
+		assert.True(t, ok)
+		assert.Equal(t, "report", e.Message)
+		assert.Equal(t, log.Fields{
+			"lambda_id":       "3f5c0a4e-f9b4-11e7-a0f6-4b8f2a3a8c8d",
+			"duration":        12.34,
+			"billed_duration": float64(100),
+			"memory_size":     float64(512),
+			"memory_used":     float64(40),
+		}, e.Fields)
+		assert.True(t, IsReport(e))
+	})
+
+	t.Run("report with init duration", func(t *testing.T) {
+		e, ok := Parse("REPORT RequestId: 3f5c0a4e\tDuration: 2.5 ms\tBilled Duration: 100 ms\tMemory Size: 128 MB\tMax Memory Used: 30 MB\tInit Duration: 150.25 ms")
+		assert.True(t, ok)
+		assert.Equal(t, 150.25, e.Fields["init_duration"])
+	})
+
+	t.Run("not lambda", func(t *testing.T) {
+		_, ok := Parse(`Server listening on port 3000`)
+		assert.False(t, ok)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		e := &log.Entry{Message: "start", Fields: log.Fields{"id": "a", "lambda_id": "b"}}
+		assert.False(t, Is(e))
+	})
+}
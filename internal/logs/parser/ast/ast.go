@@ -12,17 +12,21 @@ type Op string
 
 // Op types.
 const (
-	LNOT Op = "not"
-	NOT     = "!"
-	IN      = "in"
-	OR      = "||"
-	AND     = "&&"
-	NE      = "!="
-	EQ      = "="
-	GT      = ">"
-	LT      = "<"
-	GE      = ">="
-	LE      = "<="
+	LNOT    Op = "not"
+	NOT        = "!"
+	IN         = "in"
+	OR         = "||"
+	AND        = "&&"
+	NE         = "!="
+	EQ         = "="
+	GT         = ">"
+	LT         = "<"
+	GE         = ">="
+	LE         = "<="
+	MATCH      = "=~"
+	NOMATCH    = "!~"
+	IEQ        = "ieq"
+	INE        = "ine"
 )
 
 // Node interface.
@@ -32,7 +36,8 @@ type Node interface {
 
 // Root node.
 type Root struct {
-	Node Node
+	Node   Node
+	Stages []Node
 }
 
 // String implementation.
@@ -81,6 +86,14 @@ func (n Contains) String() string {
 	}
 }
 
+// Regexp node.
+type Regexp string
+
+// String implementation.
+func (n Regexp) String() string {
+	return fmt.Sprintf(`/%s/`, string(n))
+}
+
 // String node.
 type String string
 
@@ -135,6 +148,12 @@ type Number struct {
 
 // String implementation.
 func (n Number) String() string {
+	return strconv.FormatFloat(n.Float(), 'f', -1, 64)
+}
+
+// Float returns the value with its unit applied, bytes
+// for sizes and milliseconds for durations.
+func (n Number) Float() float64 {
 	v := n.Value
 
 	switch n.Unit {
@@ -148,7 +167,7 @@ func (n Number) String() string {
 		v *= 1000
 	}
 
-	return strconv.FormatFloat(v, 'f', -1, 64)
+	return v
 }
 
 // Binary node.
@@ -190,6 +209,77 @@ func (n Unary) String() string {
 	}
 }
 
+// Stats stage.
+type Stats struct {
+	Aggregates []Aggregate
+	By         []string
+}
+
+// String implementation.
+func (n Stats) String() string {
+	var s []string
+	for _, a := range n.Aggregates {
+		s = append(s, a.String())
+	}
+
+	if len(n.By) == 0 {
+		return fmt.Sprintf(`stats %s`, strings.Join(s, ", "))
+	}
+
+	return fmt.Sprintf(`stats %s by %s`, strings.Join(s, ", "), strings.Join(n.By, ", "))
+}
+
+// Aggregate function.
+type Aggregate struct {
+	Func  string
+	Field string
+}
+
+// String implementation.
+func (n Aggregate) String() string {
+	return fmt.Sprintf(`%s(%s)`, n.Func, n.Field)
+}
+
+// Name returns the column name of the aggregate, such as "count" or "avg_duration".
+func (n Aggregate) Name() string {
+	if n.Field == "" {
+		return n.Func
+	}
+
+	return n.Func + "_" + strings.Replace(n.Field, ".", "_", -1)
+}
+
+// Sort stage.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String implementation.
+func (n Sort) String() string {
+	if n.Desc {
+		return fmt.Sprintf(`sort %s desc`, n.Field)
+	}
+
+	return fmt.Sprintf(`sort %s asc`, n.Field)
+}
+
+// Limit stage.
+type Limit int
+
+// String implementation.
+func (n Limit) String() string {
+	return fmt.Sprintf(`limit %d`, int(n))
+}
+
+// Fields stage.
+type Fields []string
+
+// String implementation.
+func (n Fields) String() string {
+	return fmt.Sprintf(`fields %s`, strings.Join(n, ", "))
+}
+
 // value from node.
 func value(n Node) string {
 	switch v := n.(type) {
@@ -0,0 +1,254 @@
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tabular returns true if a stats or fields stage selects
+// the columns of the results, rather than raw log events.
+func (n Root) Tabular() bool {
+	for _, s := range n.Stages {
+		switch s.(type) {
+		case Stats, Fields:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Insights returns the CloudWatch Logs Insights query for the filter and
+// pipeline stages. Unless the query is tabular the timestamp and raw
+// message of matching events are selected.
+func (n Root) Insights() string {
+	var cmds []string
+
+	if !n.Tabular() {
+		cmds = append(cmds, "fields @timestamp, @message")
+	}
+
+	if n.Node != nil {
+		cmds = append(cmds, "filter "+insights(n.Node))
+	}
+
+	// columns produced by stats, which may be sorted by name
+	columns := map[string]string{}
+
+	for _, s := range n.Stages {
+		switch s := s.(type) {
+		case Stats:
+			columns = map[string]string{}
+
+			var aggs []string
+			for _, a := range s.Aggregates {
+				arg := "*"
+				if a.Field != "" {
+					arg = insightsName(a.Field)
+				}
+				aggs = append(aggs, fmt.Sprintf("%s(%s) as %s", a.Func, arg, a.Name()))
+				columns[a.Name()] = a.Name()
+			}
+
+			cmd := "stats " + strings.Join(aggs, ", ")
+
+			if len(s.By) > 0 {
+				var by []string
+				for _, name := range s.By {
+					by = append(by, insightsName(name))
+					columns[name] = insightsName(name)
+				}
+				cmd += " by " + strings.Join(by, ", ")
+			}
+
+			cmds = append(cmds, cmd)
+		case Sort:
+			name, ok := columns[s.Field]
+			if !ok {
+				name = insightsName(s.Field)
+			}
+
+			order := "asc"
+			if s.Desc {
+				order = "desc"
+			}
+
+			cmds = append(cmds, fmt.Sprintf("sort %s %s", name, order))
+		case Limit:
+			cmds = append(cmds, s.String())
+		case Fields:
+			var names []string
+			for _, name := range s {
+				names = append(names, insightsName(name))
+			}
+			cmds = append(cmds, "fields "+strings.Join(names, ", "))
+		}
+	}
+
+	return strings.Join(cmds, " | ")
+}
+
+// insights returns the Logs Insights filter expression for node n.
+func insights(n Node) string {
+	switch n := n.(type) {
+	case Expr:
+		return fmt.Sprintf(`(%s)`, insights(n.Node))
+	case Unary:
+		return fmt.Sprintf(`not (%s)`, insights(n.Right))
+	case Binary:
+		switch n.Op {
+		case AND:
+			return fmt.Sprintf(`%s and %s`, insights(n.Left), insights(n.Right))
+		case OR:
+			return fmt.Sprintf(`%s or %s`, insights(n.Left), insights(n.Right))
+		case IN:
+			var s []string
+			for _, v := range n.Right.(Tuple) {
+				s = append(s, insightsValue(v))
+			}
+			return fmt.Sprintf(`%s in [%s]`, insightsField(n.Left), strings.Join(s, ", "))
+		default:
+			return insightsCompare(n)
+		}
+	case String:
+		return insightsMatch("message", EQ, string(n))
+	default:
+		return fmt.Sprintf(`ispresent(%s)`, insightsField(n))
+	}
+}
+
+// insightsCompare returns the comparison of a binary node.
+func insightsCompare(n Binary) string {
+	left := insightsField(n.Left)
+
+	switch n.Op {
+	case MATCH:
+		return fmt.Sprintf(`%s like %s`, left, n.Right)
+	case NOMATCH:
+		return fmt.Sprintf(`%s not like %s`, left, n.Right)
+	case IEQ, INE:
+		return insightsFold(left, n)
+	}
+
+	switch v := n.Right.(type) {
+	case String:
+		return insightsMatch(left, n.Op, string(v))
+	case Field:
+		return insightsMatch(left, n.Op, string(v))
+	case Contains:
+		if s, ok := v.Node.(String); ok {
+			return insightsMatch(left, n.Op, "*"+string(s)+"*")
+		}
+		return fmt.Sprintf(`%s %s %s`, left, n.Op, insightsValue(v.Node))
+	default:
+		return fmt.Sprintf(`%s %s %s`, left, n.Op, insightsValue(v))
+	}
+}
+
+// insightsMatch returns a string comparison, using
+// a regular expression for wildcard equality.
+func insightsMatch(left string, op Op, s string) string {
+	if !strings.Contains(s, "*") || (op != EQ && op != NE) {
+		return fmt.Sprintf(`%s %s %s`, left, op, strconv.Quote(s))
+	}
+
+	if op == NE {
+		return fmt.Sprintf(`%s not like %s`, left, insightsPattern(s))
+	}
+
+	return fmt.Sprintf(`%s like %s`, left, insightsPattern(s))
+}
+
+// insightsFold returns a case-insensitive comparison, using a
+// regular expression for strings.
+func insightsFold(left string, n Binary) string {
+	var s string
+
+	switch v := n.Right.(type) {
+	case String:
+		s = string(v)
+	case Field:
+		s = string(v)
+	case Contains:
+		if v, ok := v.Node.(String); ok {
+			s = "*" + string(v) + "*"
+			break
+		}
+		return insightsCompare(Binary{Op: equality(n.Op), Left: n.Left, Right: v.Node})
+	default:
+		return insightsCompare(Binary{Op: equality(n.Op), Left: n.Left, Right: v})
+	}
+
+	re := "/(?i)" + strings.TrimPrefix(insightsPattern(s), "/")
+
+	if n.Op == INE {
+		return fmt.Sprintf(`%s not like %s`, left, re)
+	}
+
+	return fmt.Sprintf(`%s like %s`, left, re)
+}
+
+// equality returns the plain equality operator corresponding
+// to a regular expression or case-insensitive operator.
+func equality(op Op) Op {
+	switch op {
+	case NOMATCH, INE:
+		return NE
+	default:
+		return EQ
+	}
+}
+
+// insightsPattern returns a regular expression literal for a wildcard pattern.
+func insightsPattern(s string) string {
+	parts := strings.Split(s, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	re := "^" + strings.Join(parts, ".*") + "$"
+	return "/" + strings.Replace(re, "/", `\/`, -1) + "/"
+}
+
+// insightsValue returns a literal value.
+func insightsValue(n Node) string {
+	switch v := n.(type) {
+	case String:
+		return strconv.Quote(string(v))
+	case Field:
+		return strconv.Quote(string(v))
+	default:
+		return n.String()
+	}
+}
+
+// insightsField returns the name of a property or field node.
+func insightsField(n Node) string {
+	switch v := n.(type) {
+	case Property:
+		return insightsName(string(v))
+	case Field:
+		return insightsName(string(v))
+	case Member:
+		return fmt.Sprintf(`%s.%s`, insightsField(v.Left), v.Right)
+	case Subscript:
+		return fmt.Sprintf(`%s.%s`, insightsField(v.Left), v.Right)
+	default:
+		return insights(n)
+	}
+}
+
+// insightsName returns the name of a field, where log
+// fields are nested in "fields" of the JSON event.
+func insightsName(s string) string {
+	switch s {
+	case "timestamp":
+		return "@timestamp"
+	case "level", "message":
+		return s
+	default:
+		return "fields." + s
+	}
+}
@@ -0,0 +1,141 @@
+package ast
+
+import (
+	"regexp/syntax"
+	"strings"
+)
+
+// Pattern returns the CloudWatch filter pattern for the query. Comparisons
+// which filter patterns cannot express, such as most regular expressions or
+// case-insensitive equality, are relaxed so that the pattern matches a
+// superset of the logs, in which case false is returned and the logs
+// must also be filtered client-side.
+func (n Root) Pattern() (string, bool) {
+	if n.Node == nil {
+		return "", true
+	}
+
+	node, exact := pattern(n.Node)
+	if node == nil {
+		return "", false
+	}
+
+	return Root{Node: node}.String(), exact
+}
+
+// pattern returns node n using filter pattern comparisons only, the node
+// is nil when it cannot be expressed at all and may match any log.
+func pattern(n Node) (Node, bool) {
+	switch n := n.(type) {
+	case Expr:
+		node, exact := pattern(n.Node)
+		if node == nil {
+			return nil, false
+		}
+		return Expr{Node: node}, exact
+	case Unary:
+		// the negation of a superset is not a superset
+		node, exact := pattern(n.Right)
+		if !exact {
+			return nil, false
+		}
+		return Unary{Op: n.Op, Right: node}, true
+	case Binary:
+		switch n.Op {
+		case AND:
+			left, lexact := pattern(n.Left)
+			right, rexact := pattern(n.Right)
+
+			switch {
+			case left == nil:
+				return right, false
+			case right == nil:
+				return left, false
+			}
+
+			return Binary{Op: AND, Left: left, Right: right}, lexact && rexact
+		case OR:
+			left, lexact := pattern(n.Left)
+			right, rexact := pattern(n.Right)
+
+			if left == nil || right == nil {
+				return nil, false
+			}
+
+			return Binary{Op: OR, Left: left, Right: right}, lexact && rexact
+		case MATCH, NOMATCH:
+			s, ok := glob(string(n.Right.(Regexp)))
+			if !ok {
+				return nil, false
+			}
+
+			return Binary{Op: equality(n.Op), Left: n.Left, Right: String(s)}, true
+		case IEQ, INE:
+			if !caseless(n.Right) {
+				return nil, false
+			}
+
+			return Binary{Op: equality(n.Op), Left: n.Left, Right: n.Right}, true
+		}
+	}
+
+	return n, true
+}
+
+// glob returns the wildcard pattern equivalent to regular expression
+// re, which is possible for literals optionally anchored by ^ and $.
+func glob(re string) (string, bool) {
+	r, err := syntax.Parse(re, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	subs := []*syntax.Regexp{r}
+	if r.Op == syntax.OpConcat {
+		subs = r.Sub
+	}
+
+	prefix, suffix := "*", "*"
+
+	if len(subs) > 0 && subs[0].Op == syntax.OpBeginText {
+		prefix = ""
+		subs = subs[1:]
+	}
+
+	if len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText {
+		suffix = ""
+		subs = subs[:len(subs)-1]
+	}
+
+	var s string
+
+	switch {
+	case len(subs) == 0:
+	case len(subs) == 1 && subs[0].Op == syntax.OpLiteral && subs[0].Flags&syntax.FoldCase == 0:
+		s = string(subs[0].Rune)
+	default:
+		return "", false
+	}
+
+	if strings.Contains(s, "*") {
+		return "", false
+	}
+
+	return prefix + s + suffix, true
+}
+
+// caseless returns true if the value of node n is unaffected by case.
+func caseless(n Node) bool {
+	switch n := n.(type) {
+	case String:
+		return strings.ToLower(string(n)) == strings.ToUpper(string(n))
+	case Field:
+		return strings.ToLower(string(n)) == strings.ToUpper(string(n))
+	case Contains:
+		return caseless(n.Node)
+	case Number:
+		return true
+	default:
+		return false
+	}
+}
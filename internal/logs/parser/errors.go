@@ -0,0 +1,283 @@
+package parser
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError is a query syntax error.
+type SyntaxError struct {
+	// Query is the query text.
+	Query string
+
+	// Offset is the position of the error in runes.
+	Offset int
+
+	// Message describes the error, defaulting to the unexpected input.
+	Message string
+
+	// Expected is a list of the tokens which would be valid at the offset.
+	Expected []string
+
+	// Hint is a suggestion for fixing a common mistake.
+	Hint string
+}
+
+// Error implementation.
+func (e *SyntaxError) Error() string {
+	line, column, text := e.position()
+
+	msg := e.Message
+	if msg == "" {
+		msg = "unexpected " + e.near()
+	}
+
+	s := fmt.Sprintf("%s at column %d", msg, column)
+	if strings.Count(e.Query, "\n") > 0 {
+		s = fmt.Sprintf("%s at line %d column %d", msg, line, column)
+	}
+
+	if len(e.Expected) > 0 {
+		s += ", expected " + list(e.Expected)
+	}
+
+	text = strings.Replace(text, "\t", " ", -1)
+	s += fmt.Sprintf("\n\n  %s\n  %s^", text, strings.Repeat(" ", column-1))
+
+	if e.Hint != "" {
+		s += "\n\n" + e.Hint
+	}
+
+	return s
+}
+
+// position returns the line and column of the error, and the text of its line.
+func (e *SyntaxError) position() (int, int, string) {
+	runes := []rune(e.Query)
+	line, start := 1, 0
+
+	for i := 0; i < e.Offset && i < len(runes); i++ {
+		if runes[i] == '\n' {
+			line, start = line+1, i+1
+		}
+	}
+
+	end := start
+	for end < len(runes) && runes[end] != '\n' {
+		end++
+	}
+
+	return line, e.Offset - start + 1, string(runes[start:end])
+}
+
+// near returns a description of the input at the error.
+func (e *SyntaxError) near() string {
+	if w := word(e.Query, e.Offset); w != "" {
+		return fmt.Sprintf("%q", w)
+	}
+
+	return "end of query"
+}
+
+// candidates are the tokens suggested when parsing fails, with a sample
+// which is inserted at the error to determine if the token is valid.
+var candidates = []struct {
+	name   string
+	sample string
+}{
+	{`")"`, `)`},
+	{`","`, `,`},
+	{`"and"`, ` and`},
+	{`"or"`, ` or`},
+	{`"|"`, ` |`},
+	{`an operator`, ` =`},
+	{`a value`, ` "x"`},
+}
+
+// completions are appended to candidates to form a valid query.
+var completions = []string{
+	``,
+	` x`,
+	`)`,
+	` x)`,
+	`))`,
+	` x))`,
+	` "x")`,
+	` limit 1`,
+}
+
+// keywords which are lowercase.
+var keywords = map[string]bool{
+	"and":       true,
+	"or":        true,
+	"not":       true,
+	"in":        true,
+	"contains":  true,
+	"matches":   true,
+	"ieq":       true,
+	"ine":       true,
+	"icontains": true,
+}
+
+// operators which are mistaken for valid ones.
+var operators = map[string]string{
+	"===": "=",
+	"!==": "!=",
+	"<>":  "!=",
+	"=<":  "<=",
+	"=>":  ">=",
+	"&":   "and",
+	"&&&": "&&",
+}
+
+// syntaxError returns a syntax error for query s which failed to parse at offset.
+func syntaxError(s string, offset int) *SyntaxError {
+	return &SyntaxError{
+		Query:    s,
+		Offset:   offset,
+		Expected: expected(s, offset),
+		Hint:     hint(s, offset),
+	}
+}
+
+// regexpError returns a syntax error for the invalid regular expression at offset.
+func regexpError(s string, offset int, err error) *SyntaxError {
+	msg := "invalid regular expression"
+	if e, ok := err.(*syntax.Error); ok {
+		msg += ", " + e.Code.String()
+	}
+
+	return &SyntaxError{
+		Query:   s,
+		Offset:  offset,
+		Message: msg,
+	}
+}
+
+// keywordError returns a syntax error for keyword k written in the wrong case at offset.
+func keywordError(s string, offset int, k string) *SyntaxError {
+	return &SyntaxError{
+		Query:  s,
+		Offset: offset,
+		Hint:   fmt.Sprintf("Did you mean %q? Keywords are lowercase.", k),
+	}
+}
+
+// expected returns the candidate tokens which are valid at offset,
+// that is the query up to the offset followed by the token may be
+// completed to form a valid query.
+func expected(s string, offset int) (tokens []string) {
+	prefix := string([]rune(s)[:offset])
+
+	if strings.TrimSpace(prefix) != "" && valid(prefix) {
+		tokens = append(tokens, "end of query")
+	}
+
+	for _, c := range candidates {
+		for _, end := range completions {
+			if valid(prefix + c.sample + end) {
+				tokens = append(tokens, c.name)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// valid returns true if query s parses.
+func valid(s string) bool {
+	p := &parser{Buffer: s}
+	p.Init()
+	return p.Parse() == nil
+}
+
+// hint returns a suggestion for common mistakes at offset.
+func hint(s string, offset int) string {
+	runes := []rune(s)
+	prefix := strings.TrimRightFunc(string(runes[:offset]), unicode.IsSpace)
+	w := word(s, offset)
+
+	// operators
+	op := tail(prefix, isOperator) + lead(w, isOperator)
+	if v, ok := operators[op]; ok {
+		return fmt.Sprintf("Did you mean %q instead of %q?", v, op)
+	}
+
+	// unterminated strings
+	if (strings.Count(s, `"`)-strings.Count(s, `\"`))%2 == 1 {
+		return "Strings must be terminated with a closing quote."
+	}
+
+	// unquoted strings with special characters
+	value := tail(prefix, isValue) + w
+	if w != "" && isValue([]rune(w)[0]) && strings.IndexFunc(value, isSpecial) != -1 {
+		return fmt.Sprintf("Did you mean %q? Strings containing special characters must be quoted.", value)
+	}
+
+	return ""
+}
+
+// word returns the text at offset up to the next whitespace.
+func word(s string, offset int) string {
+	runes := []rune(s)
+	if offset >= len(runes) {
+		return ""
+	}
+
+	end := offset
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+
+	return string(runes[offset:end])
+}
+
+// tail returns the trailing runes of s satisfying fn.
+func tail(s string, fn func(rune) bool) string {
+	runes := []rune(s)
+	i := len(runes)
+	for i > 0 && fn(runes[i-1]) {
+		i--
+	}
+	return string(runes[i:])
+}
+
+// lead returns the leading runes of s satisfying fn.
+func lead(s string, fn func(rune) bool) string {
+	runes := []rune(s)
+	i := 0
+	for i < len(runes) && fn(runes[i]) {
+		i++
+	}
+	return string(runes[:i])
+}
+
+// isSpecial returns true if r may not be used in unquoted strings.
+func isSpecial(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("/_.", r)
+}
+
+// isOperator returns true if r is an operator character.
+func isOperator(r rune) bool {
+	return strings.ContainsRune("=!<>&|~", r)
+}
+
+// isValue returns true if r may be part of an unquoted value.
+func isValue(r rune) bool {
+	return !unicode.IsSpace(r) && !isOperator(r) && !strings.ContainsRune(`(),"`, r)
+}
+
+// list returns a list of tokens in prose.
+func list(tokens []string) string {
+	switch n := len(tokens); n {
+	case 1:
+		return tokens[0]
+	case 2:
+		return tokens[0] + " or " + tokens[1]
+	default:
+		return strings.Join(tokens[:n-1], ", ") + ", or " + tokens[n-1]
+	}
+}
@@ -94,6 +94,31 @@ const (
 	ruleWhitespace
 	ruleEOL
 	ruleEOF
+	rulePipeline
+	ruleStatsCommand
+	ruleAggregate
+	ruleFunction
+	ruleGroupField
+	ruleSortCommand
+	ruleLimitCommand
+	ruleFieldsCommand
+	ruleFieldName
+	rulePIPE
+	ruleSTATS
+	ruleBY
+	ruleSORT
+	ruleASC
+	ruleDESC
+	ruleLIMIT
+	ruleFIELDS
+	ruleRegexp
+	ruleRegexpChar
+	ruleMATCHES
+	ruleIEQ
+	ruleINE
+	ruleICONTAINS
+	ruleMATCH
+	ruleNOMATCH
 	ruleAction0
 	ruleAction1
 	ruleAction2
@@ -127,6 +152,23 @@ const (
 	ruleAction30
 	rulePegText
 	ruleAction31
+	ruleAction32
+	ruleAction33
+	ruleAction34
+	ruleAction35
+	ruleAction36
+	ruleAction37
+	ruleAction38
+	ruleAction39
+	ruleAction40
+	ruleAction41
+	ruleAction42
+	ruleAction43
+	ruleAction44
+	ruleAction45
+	ruleAction46
+	ruleAction47
+	ruleAction48
 )
 
 var rul3s = [...]string{
@@ -210,6 +252,31 @@ var rul3s = [...]string{
 	"Whitespace",
 	"EOL",
 	"EOF",
+	"Pipeline",
+	"StatsCommand",
+	"Aggregate",
+	"Function",
+	"GroupField",
+	"SortCommand",
+	"LimitCommand",
+	"FieldsCommand",
+	"FieldName",
+	"PIPE",
+	"STATS",
+	"BY",
+	"SORT",
+	"ASC",
+	"DESC",
+	"LIMIT",
+	"FIELDS",
+	"Regexp",
+	"RegexpChar",
+	"MATCHES",
+	"IEQ",
+	"INE",
+	"ICONTAINS",
+	"MATCH",
+	"NOMATCH",
 	"Action0",
 	"Action1",
 	"Action2",
@@ -243,6 +310,23 @@ var rul3s = [...]string{
 	"Action30",
 	"PegText",
 	"Action31",
+	"Action32",
+	"Action33",
+	"Action34",
+	"Action35",
+	"Action36",
+	"Action37",
+	"Action38",
+	"Action39",
+	"Action40",
+	"Action41",
+	"Action42",
+	"Action43",
+	"Action44",
+	"Action45",
+	"Action46",
+	"Action47",
+	"Action48",
 }
 
 type token32 struct {
@@ -355,12 +439,16 @@ func (t *tokens32) Tokens() []token32 {
 }
 
 type parser struct {
-	stack  []ast.Node
-	number string
+	stack    []ast.Node
+	stages   []ast.Node
+	number   string
+	function string
+	argument string
+	err      error
 
 	Buffer string
 	buffer []rune
-	rules  [113]func() bool
+	rules  [155]func() bool
 	parse  func(rule ...int) error
 	reset  func()
 	Pretty bool
@@ -502,7 +590,7 @@ func (p *parser) Execute() {
 		case ruleAction23:
 			p.AddBinary(ast.EQ)
 		case ruleAction24:
-			p.AddBinaryContains()
+			p.AddBinaryContains(ast.EQ)
 		case ruleAction25:
 			p.AddBinary(ast.AND)
 		case ruleAction26:
@@ -517,6 +605,40 @@ func (p *parser) Execute() {
 			p.AddUnary(ast.LNOT)
 		case ruleAction31:
 			p.SetNumber(text)
+		case ruleAction32:
+			p.AddStats()
+		case ruleAction33:
+			p.SetArgument(text)
+		case ruleAction34:
+			p.AddAggregate()
+		case ruleAction35:
+			p.SetFunction(text)
+		case ruleAction36:
+			p.AddGroup(text)
+		case ruleAction37:
+			p.AddSort(text)
+		case ruleAction38:
+			p.SetDescending()
+		case ruleAction39:
+			p.AddLimit(text)
+		case ruleAction40:
+			p.AddFields()
+		case ruleAction41:
+			p.AddProjection(text)
+		case ruleAction42:
+			p.AddProjection(text)
+		case ruleAction43:
+			p.AddRegexp(text, begin, ast.MATCH)
+		case ruleAction44:
+			p.AddRegexp(text, begin, ast.NOMATCH)
+		case ruleAction45:
+			p.AddRegexp(text, begin, ast.MATCH)
+		case ruleAction46:
+			p.AddBinary(ast.IEQ)
+		case ruleAction47:
+			p.AddBinary(ast.INE)
+		case ruleAction48:
+			p.AddBinaryContains(ast.IEQ)
 
 		}
 	}
@@ -591,38 +713,55 @@ func (p *parser) Init() {
 
 	_rules = [...]func() bool{
 		nil,
-		/* 0 Query <- <(_ Expr _ EOF)> */
+		/* 0 Query <- <(_ (Expr _)? Pipeline* EOF)> */
 		func() bool {
-			position0, tokenIndex0 := position, tokenIndex
+			position344, tokenIndex344 := position, tokenIndex
 			{
-				position1 := position
+				position345 := position
 				if !_rules[rule_]() {
-					goto l0
+					goto l344
 				}
-				if !_rules[ruleExpr]() {
-					goto l0
+				{
+					position346, tokenIndex346 := position, tokenIndex
+					if !_rules[ruleExpr]() {
+						goto l346
+					}
+					if !_rules[rule_]() {
+						goto l346
+					}
+					goto l347
+				l346:
+					position, tokenIndex = position346, tokenIndex346
 				}
-				if !_rules[rule_]() {
-					goto l0
+			l347:
+			l348:
+				{
+					position349, tokenIndex349 := position, tokenIndex
+					if !_rules[rulePipeline]() {
+						goto l349
+					}
+					goto l348
+				l349:
+					position, tokenIndex = position349, tokenIndex349
 				}
 				{
-					position2 := position
+					position350 := position
 					{
-						position3, tokenIndex3 := position, tokenIndex
+						position351, tokenIndex351 := position, tokenIndex
 						if !matchDot() {
-							goto l3
+							goto l351
 						}
-						goto l0
-					l3:
-						position, tokenIndex = position3, tokenIndex3
+						goto l344
+					l351:
+						position, tokenIndex = position351, tokenIndex351
 					}
-					add(ruleEOF, position2)
+					add(ruleEOF, position350)
 				}
-				add(ruleQuery, position1)
+				add(ruleQuery, position345)
 			}
 			return true
-		l0:
-			position, tokenIndex = position0, tokenIndex0
+		l344:
+			position, tokenIndex = position344, tokenIndex344
 			return false
 		},
 		/* 1 PrimaryExpr <- <((Numbers Unit _ Action0) / (Severity Action2) / (Stage Action3) / (Id Action4) / ((&('(') (LPAR Expr RPAR Action7)) | (&('"') (String Action5)) | (&('\t' | '\n' | '\r' | ' ' | '.' | '0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') (Numbers _ Action1)) | (&('/' | 'A' | 'B' | 'C' | 'D' | 'E' | 'F' | 'G' | 'H' | 'I' | 'J' | 'K' | 'L' | 'M' | 'N' | 'O' | 'P' | 'Q' | 'R' | 'S' | 'T' | 'U' | 'V' | 'W' | 'X' | 'Y' | 'Z' | '_' | 'a' | 'b' | 'c' | 'd' | 'e' | 'f' | 'g' | 'h' | 'i' | 'j' | 'k' | 'l' | 'm' | 'n' | 'o' | 'p' | 'q' | 'r' | 's' | 't' | 'u' | 'v' | 'w' | 'x' | 'y' | 'z') (UnquotedString Action6))))> */
@@ -2012,157 +2151,224 @@ func (p *parser) Init() {
 			position, tokenIndex = position146, tokenIndex146
 			return false
 		},
-		/* 8 EqualityExpr <- <(RelationalExpr ((EQEQ RelationalExpr Action21) / ((&('c') (CONTAINS RelationalExpr Action24)) | (&('=') (EQ RelationalExpr Action23)) | (&('!') (NE RelationalExpr Action22))))*)> */
+		/* 8 EqualityExpr <- <(RelationalExpr ((EQEQ RelationalExpr Action21) / (NE RelationalExpr Action22) / (MATCH Regexp Action43) / (NOMATCH Regexp Action44) / (EQ RelationalExpr Action23) / (CONTAINS RelationalExpr Action24) / (MATCHES Regexp Action45) / (IEQ RelationalExpr Action46) / (INE RelationalExpr Action47) / (ICONTAINS RelationalExpr Action48))*)> */
 		func() bool {
-			position164, tokenIndex164 := position, tokenIndex
+			position434, tokenIndex434 := position, tokenIndex
 			{
-				position165 := position
+				position435 := position
 				if !_rules[ruleRelationalExpr]() {
-					goto l164
+					goto l434
 				}
-			l166:
+			l436:
 				{
-					position167, tokenIndex167 := position, tokenIndex
+					position437, tokenIndex437 := position, tokenIndex
 					{
-						position168, tokenIndex168 := position, tokenIndex
+						position438, tokenIndex438 := position, tokenIndex
 						{
-							position170 := position
+							position440 := position
 							if buffer[position] != rune('=') {
-								goto l169
+								goto l439
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l169
+								goto l439
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l169
+								goto l439
 							}
-							add(ruleEQEQ, position170)
+							add(ruleEQEQ, position440)
 						}
 						if !_rules[ruleRelationalExpr]() {
-							goto l169
+							goto l439
 						}
 						{
 							add(ruleAction21, position)
 						}
-						goto l168
-					l169:
-						position, tokenIndex = position168, tokenIndex168
+						goto l438
+					l439:
+						position, tokenIndex = position438, tokenIndex438
 						{
-							switch buffer[position] {
-							case 'c':
-								{
-									position173 := position
-									if buffer[position] != rune('c') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('o') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('n') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('t') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('a') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('i') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('n') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('s') {
-										goto l167
-									}
-									position++
-									{
-										position174, tokenIndex174 := position, tokenIndex
-										if !_rules[ruleIdChar]() {
-											goto l174
-										}
-										goto l167
-									l174:
-										position, tokenIndex = position174, tokenIndex174
-									}
-									if !_rules[rule_]() {
-										goto l167
-									}
-									add(ruleCONTAINS, position173)
-								}
-								if !_rules[ruleRelationalExpr]() {
-									goto l167
-								}
-								{
-									add(ruleAction24, position)
-								}
-								break
-							case '=':
-								{
-									position176 := position
-									if buffer[position] != rune('=') {
-										goto l167
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l167
-									}
-									add(ruleEQ, position176)
-								}
-								if !_rules[ruleRelationalExpr]() {
-									goto l167
-								}
-								{
-									add(ruleAction23, position)
-								}
-								break
-							default:
-								{
-									position178 := position
-									if buffer[position] != rune('!') {
-										goto l167
-									}
-									position++
-									if buffer[position] != rune('=') {
-										goto l167
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l167
-									}
-									add(ruleNE, position178)
-								}
-								if !_rules[ruleRelationalExpr]() {
-									goto l167
-								}
-								{
-									add(ruleAction22, position)
+							position442 := position
+							if buffer[position] != rune('!') {
+								goto l441
+							}
+							position++
+							if buffer[position] != rune('=') {
+								goto l441
+							}
+							position++
+							if !_rules[rule_]() {
+								goto l441
+							}
+							add(ruleNE, position442)
+						}
+						if !_rules[ruleRelationalExpr]() {
+							goto l441
+						}
+						{
+							add(ruleAction22, position)
+						}
+						goto l438
+					l441:
+						position, tokenIndex = position438, tokenIndex438
+						if !_rules[ruleMATCH]() {
+							goto l443
+						}
+						if !_rules[ruleRegexp]() {
+							goto l443
+						}
+						{
+							add(ruleAction43, position)
+						}
+						goto l438
+					l443:
+						position, tokenIndex = position438, tokenIndex438
+						if !_rules[ruleNOMATCH]() {
+							goto l444
+						}
+						if !_rules[ruleRegexp]() {
+							goto l444
+						}
+						{
+							add(ruleAction44, position)
+						}
+						goto l438
+					l444:
+						position, tokenIndex = position438, tokenIndex438
+						{
+							position446 := position
+							if buffer[position] != rune('=') {
+								goto l445
+							}
+							position++
+							if !_rules[rule_]() {
+								goto l445
+							}
+							add(ruleEQ, position446)
+						}
+						if !_rules[ruleRelationalExpr]() {
+							goto l445
+						}
+						{
+							add(ruleAction23, position)
+						}
+						goto l438
+					l445:
+						position, tokenIndex = position438, tokenIndex438
+						{
+							position448 := position
+							if buffer[position] != rune('c') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('o') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('n') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('t') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('a') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('i') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('n') {
+								goto l447
+							}
+							position++
+							if buffer[position] != rune('s') {
+								goto l447
+							}
+							position++
+							{
+								position449, tokenIndex449 := position, tokenIndex
+								if !_rules[ruleIdChar]() {
+									goto l449
 								}
-								break
+								goto l447
+							l449:
+								position, tokenIndex = position449, tokenIndex449
 							}
+							if !_rules[rule_]() {
+								goto l447
+							}
+							add(ruleCONTAINS, position448)
+						}
+						if !_rules[ruleRelationalExpr]() {
+							goto l447
+						}
+						{
+							add(ruleAction24, position)
+						}
+						goto l438
+					l447:
+						position, tokenIndex = position438, tokenIndex438
+						if !_rules[ruleMATCHES]() {
+							goto l450
+						}
+						if !_rules[ruleRegexp]() {
+							goto l450
+						}
+						{
+							add(ruleAction45, position)
+						}
+						goto l438
+					l450:
+						position, tokenIndex = position438, tokenIndex438
+						if !_rules[ruleIEQ]() {
+							goto l451
+						}
+						if !_rules[ruleRelationalExpr]() {
+							goto l451
+						}
+						{
+							add(ruleAction46, position)
+						}
+						goto l438
+					l451:
+						position, tokenIndex = position438, tokenIndex438
+						if !_rules[ruleINE]() {
+							goto l452
+						}
+						if !_rules[ruleRelationalExpr]() {
+							goto l452
+						}
+						{
+							add(ruleAction47, position)
+						}
+						goto l438
+					l452:
+						position, tokenIndex = position438, tokenIndex438
+						if !_rules[ruleICONTAINS]() {
+							goto l437
+						}
+						if !_rules[ruleRelationalExpr]() {
+							goto l437
+						}
+						{
+							add(ruleAction48, position)
 						}
-
 					}
-				l168:
-					goto l166
-				l167:
-					position, tokenIndex = position167, tokenIndex167
+				l438:
+					goto l436
+				l437:
+					position, tokenIndex = position437, tokenIndex437
 				}
-				add(ruleEqualityExpr, position165)
+				add(ruleEqualityExpr, position435)
 			}
 			return true
-		l164:
-			position, tokenIndex = position164, tokenIndex164
+		l434:
+			position, tokenIndex = position434, tokenIndex434
 			return false
 		},
 		/* 9 LogicalAndExpr <- <(EqualityExpr ((AND EqualityExpr Action25) / (ANDAND EqualityExpr Action26) / (_ EqualityExpr Action27))*)> */
@@ -2902,598 +3108,1898 @@ func (p *parser) Init() {
 		nil,
 		/* 57 FATAL <- <(<('f' 'a' 't' 'a' 'l')> !IdChar _)> */
 		nil,
-		/* 58 Keyword <- <((('s' 't' 'a' 'g' 'i' 'n' 'g') / ('d' 'e' 'v' 'e' 'l' 'o' 'p' 'm' 'e' 'n' 't') / ('i' 'n' 'f' 'o') / ('m' 'b') / ((&('s') 's') | (&('m') ('m' 's')) | (&('b') 'b') | (&('k') ('k' 'b')) | (&('g') ('g' 'b')) | (&('i') ('i' 'n')) | (&('f') ('f' 'a' 't' 'a' 'l')) | (&('e') ('e' 'r' 'r' 'o' 'r')) | (&('w') ('w' 'a' 'r' 'n')) | (&('d') ('d' 'e' 'b' 'u' 'g')) | (&('c') ('c' 'o' 'n' 't' 'a' 'i' 'n' 's')) | (&('n') ('n' 'o' 't')) | (&('a') ('a' 'n' 'd')) | (&('o') ('o' 'r')) | (&('p') ('p' 'r' 'o' 'd' 'u' 'c' 't' 'i' 'o' 'n')))) !IdChar)> */
+		/* 58 Keyword <- <((('p' 'r' 'o' 'd' 'u' 'c' 't' 'i' 'o' 'n') / ('s' 't' 'a' 'g' 'i' 'n' 'g') / ('d' 'e' 'v' 'e' 'l' 'o' 'p' 'm' 'e' 'n' 't') / ('o' 'r') / ('a' 'n' 'd') / ('n' 'o' 't') / ('c' 'o' 'n' 't' 'a' 'i' 'n' 's') / ('m' 'a' 't' 'c' 'h' 'e' 's') / ('i' 'c' 'o' 'n' 't' 'a' 'i' 'n' 's') / ('i' 'e' 'q') / ('i' 'n' 'e') / ('d' 'e' 'b' 'u' 'g') / ('i' 'n' 'f' 'o') / ('w' 'a' 'r' 'n') / ('e' 'r' 'r' 'o' 'r') / ('f' 'a' 't' 'a' 'l') / ('i' 'n') / ('g' 'b') / ('m' 'b') / ('k' 'b') / 'b' / ('m' 's') / 's') !IdChar)> */
 		func() bool {
-			position307, tokenIndex307 := position, tokenIndex
+			position453, tokenIndex453 := position, tokenIndex
 			{
-				position308 := position
+				position454 := position
 				{
-					position309, tokenIndex309 := position, tokenIndex
+					position455, tokenIndex455 := position, tokenIndex
+					if buffer[position] != rune('p') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('r') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('o') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('d') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('u') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('c') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('t') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('i') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('o') {
+						goto l456
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l456
+					}
+					position++
+					goto l455
+				l456:
+					position, tokenIndex = position455, tokenIndex455
 					if buffer[position] != rune('s') {
-						goto l310
+						goto l457
 					}
 					position++
 					if buffer[position] != rune('t') {
-						goto l310
+						goto l457
 					}
 					position++
 					if buffer[position] != rune('a') {
-						goto l310
+						goto l457
 					}
 					position++
 					if buffer[position] != rune('g') {
-						goto l310
+						goto l457
 					}
 					position++
 					if buffer[position] != rune('i') {
-						goto l310
+						goto l457
 					}
 					position++
 					if buffer[position] != rune('n') {
-						goto l310
+						goto l457
 					}
 					position++
 					if buffer[position] != rune('g') {
-						goto l310
+						goto l457
 					}
 					position++
-					goto l309
-				l310:
-					position, tokenIndex = position309, tokenIndex309
+					goto l455
+				l457:
+					position, tokenIndex = position455, tokenIndex455
 					if buffer[position] != rune('d') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('e') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('v') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('e') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('l') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('o') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('p') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('m') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('e') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('n') {
-						goto l311
+						goto l458
 					}
 					position++
 					if buffer[position] != rune('t') {
-						goto l311
+						goto l458
 					}
 					position++
-					goto l309
-				l311:
-					position, tokenIndex = position309, tokenIndex309
-					if buffer[position] != rune('i') {
-						goto l312
+					goto l455
+				l458:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('o') {
+						goto l459
+					}
+					position++
+					if buffer[position] != rune('r') {
+						goto l459
+					}
+					position++
+					goto l455
+				l459:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('a') {
+						goto l460
 					}
 					position++
 					if buffer[position] != rune('n') {
-						goto l312
+						goto l460
 					}
 					position++
-					if buffer[position] != rune('f') {
-						goto l312
+					if buffer[position] != rune('d') {
+						goto l460
+					}
+					position++
+					goto l455
+				l460:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('n') {
+						goto l461
+					}
+					position++
+					if buffer[position] != rune('o') {
+						goto l461
+					}
+					position++
+					if buffer[position] != rune('t') {
+						goto l461
+					}
+					position++
+					goto l455
+				l461:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('c') {
+						goto l462
 					}
 					position++
 					if buffer[position] != rune('o') {
-						goto l312
+						goto l462
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l462
 					}
 					position++
-					goto l309
-				l312:
-					position, tokenIndex = position309, tokenIndex309
+					if buffer[position] != rune('t') {
+						goto l462
+					}
+					position++
+					if buffer[position] != rune('a') {
+						goto l462
+					}
+					position++
+					if buffer[position] != rune('i') {
+						goto l462
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l462
+					}
+					position++
+					if buffer[position] != rune('s') {
+						goto l462
+					}
+					position++
+					goto l455
+				l462:
+					position, tokenIndex = position455, tokenIndex455
 					if buffer[position] != rune('m') {
-						goto l313
+						goto l463
 					}
 					position++
-					if buffer[position] != rune('b') {
-						goto l313
+					if buffer[position] != rune('a') {
+						goto l463
 					}
 					position++
-					goto l309
-				l313:
-					position, tokenIndex = position309, tokenIndex309
-					{
-						switch buffer[position] {
-						case 's':
-							if buffer[position] != rune('s') {
-								goto l307
-							}
-							position++
-							break
-						case 'm':
-							if buffer[position] != rune('m') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('s') {
-								goto l307
-							}
-							position++
-							break
-						case 'b':
-							if buffer[position] != rune('b') {
-								goto l307
-							}
-							position++
-							break
-						case 'k':
-							if buffer[position] != rune('k') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('b') {
-								goto l307
-							}
-							position++
-							break
-						case 'g':
-							if buffer[position] != rune('g') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('b') {
-								goto l307
-							}
-							position++
-							break
-						case 'i':
-							if buffer[position] != rune('i') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('n') {
-								goto l307
-							}
-							position++
-							break
-						case 'f':
-							if buffer[position] != rune('f') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('a') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('t') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('a') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('l') {
-								goto l307
-							}
-							position++
-							break
-						case 'e':
-							if buffer[position] != rune('e') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('r') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('r') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('o') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('r') {
-								goto l307
-							}
-							position++
-							break
-						case 'w':
-							if buffer[position] != rune('w') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('a') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('r') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('n') {
-								goto l307
-							}
-							position++
-							break
-						case 'd':
-							if buffer[position] != rune('d') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('e') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('b') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('u') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('g') {
-								goto l307
-							}
-							position++
-							break
-						case 'c':
-							if buffer[position] != rune('c') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('o') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('n') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('t') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('a') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('i') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('n') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('s') {
-								goto l307
-							}
-							position++
-							break
-						case 'n':
-							if buffer[position] != rune('n') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('o') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('t') {
-								goto l307
-							}
-							position++
-							break
-						case 'a':
-							if buffer[position] != rune('a') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('n') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('d') {
-								goto l307
-							}
-							position++
-							break
-						case 'o':
-							if buffer[position] != rune('o') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('r') {
-								goto l307
-							}
-							position++
-							break
-						default:
-							if buffer[position] != rune('p') {
-								goto l307
-							}
-							position++
-							if buffer[position] != rune('r') {
-								goto l307
+					if buffer[position] != rune('t') {
+						goto l463
+					}
+					position++
+					if buffer[position] != rune('c') {
+						goto l463
+					}
+					position++
+					if buffer[position] != rune('h') {
+						goto l463
+					}
+					position++
+					if buffer[position] != rune('e') {
+						goto l463
+					}
+					position++
+					if buffer[position] != rune('s') {
+						goto l463
+					}
+					position++
+					goto l455
+				l463:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('i') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('c') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('o') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('t') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('a') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('i') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l464
+					}
+					position++
+					if buffer[position] != rune('s') {
+						goto l464
+					}
+					position++
+					goto l455
+				l464:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('i') {
+						goto l465
+					}
+					position++
+					if buffer[position] != rune('e') {
+						goto l465
+					}
+					position++
+					if buffer[position] != rune('q') {
+						goto l465
+					}
+					position++
+					goto l455
+				l465:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('i') {
+						goto l466
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l466
+					}
+					position++
+					if buffer[position] != rune('e') {
+						goto l466
+					}
+					position++
+					goto l455
+				l466:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('d') {
+						goto l467
+					}
+					position++
+					if buffer[position] != rune('e') {
+						goto l467
+					}
+					position++
+					if buffer[position] != rune('b') {
+						goto l467
+					}
+					position++
+					if buffer[position] != rune('u') {
+						goto l467
+					}
+					position++
+					if buffer[position] != rune('g') {
+						goto l467
+					}
+					position++
+					goto l455
+				l467:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('i') {
+						goto l468
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l468
+					}
+					position++
+					if buffer[position] != rune('f') {
+						goto l468
+					}
+					position++
+					if buffer[position] != rune('o') {
+						goto l468
+					}
+					position++
+					goto l455
+				l468:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('w') {
+						goto l469
+					}
+					position++
+					if buffer[position] != rune('a') {
+						goto l469
+					}
+					position++
+					if buffer[position] != rune('r') {
+						goto l469
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l469
+					}
+					position++
+					goto l455
+				l469:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('e') {
+						goto l470
+					}
+					position++
+					if buffer[position] != rune('r') {
+						goto l470
+					}
+					position++
+					if buffer[position] != rune('r') {
+						goto l470
+					}
+					position++
+					if buffer[position] != rune('o') {
+						goto l470
+					}
+					position++
+					if buffer[position] != rune('r') {
+						goto l470
+					}
+					position++
+					goto l455
+				l470:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('f') {
+						goto l471
+					}
+					position++
+					if buffer[position] != rune('a') {
+						goto l471
+					}
+					position++
+					if buffer[position] != rune('t') {
+						goto l471
+					}
+					position++
+					if buffer[position] != rune('a') {
+						goto l471
+					}
+					position++
+					if buffer[position] != rune('l') {
+						goto l471
+					}
+					position++
+					goto l455
+				l471:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('i') {
+						goto l472
+					}
+					position++
+					if buffer[position] != rune('n') {
+						goto l472
+					}
+					position++
+					goto l455
+				l472:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('g') {
+						goto l473
+					}
+					position++
+					if buffer[position] != rune('b') {
+						goto l473
+					}
+					position++
+					goto l455
+				l473:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('m') {
+						goto l474
+					}
+					position++
+					if buffer[position] != rune('b') {
+						goto l474
+					}
+					position++
+					goto l455
+				l474:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('k') {
+						goto l475
+					}
+					position++
+					if buffer[position] != rune('b') {
+						goto l475
+					}
+					position++
+					goto l455
+				l475:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('b') {
+						goto l476
+					}
+					position++
+					goto l455
+				l476:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('m') {
+						goto l477
+					}
+					position++
+					if buffer[position] != rune('s') {
+						goto l477
+					}
+					position++
+					goto l455
+				l477:
+					position, tokenIndex = position455, tokenIndex455
+					if buffer[position] != rune('s') {
+						goto l453
+					}
+					position++
+				}
+			l455:
+				{
+					position478, tokenIndex478 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l478
+					}
+					goto l453
+				l478:
+					position, tokenIndex = position478, tokenIndex478
+				}
+				add(ruleKeyword, position454)
+			}
+			return true
+		l453:
+			position, tokenIndex = position453, tokenIndex453
+			return false
+		},
+		/* 59 EQ <- <('=' _)> */
+		nil,
+		/* 60 LBRK <- <('[' _)> */
+		nil,
+		/* 61 RBRK <- <(']' _)> */
+		nil,
+		/* 62 LPAR <- <('(' _)> */
+		func() bool {
+			position319, tokenIndex319 := position, tokenIndex
+			{
+				position320 := position
+				if buffer[position] != rune('(') {
+					goto l319
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l319
+				}
+				add(ruleLPAR, position320)
+			}
+			return true
+		l319:
+			position, tokenIndex = position319, tokenIndex319
+			return false
+		},
+		/* 63 RPAR <- <(')' _)> */
+		func() bool {
+			position321, tokenIndex321 := position, tokenIndex
+			{
+				position322 := position
+				if buffer[position] != rune(')') {
+					goto l321
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l321
+				}
+				add(ruleRPAR, position322)
+			}
+			return true
+		l321:
+			position, tokenIndex = position321, tokenIndex321
+			return false
+		},
+		/* 64 DOT <- <('.' _)> */
+		nil,
+		/* 65 BANG <- <('!' !'=' _)> */
+		nil,
+		/* 66 LT <- <('<' !'=' _)> */
+		nil,
+		/* 67 GT <- <('>' !'=' _)> */
+		nil,
+		/* 68 LE <- <('<' '=' _)> */
+		nil,
+		/* 69 EQEQ <- <('=' '=' _)> */
+		nil,
+		/* 70 GE <- <('>' '=' _)> */
+		nil,
+		/* 71 NE <- <('!' '=' _)> */
+		nil,
+		/* 72 ANDAND <- <('&' '&' _)> */
+		nil,
+		/* 73 OROR <- <('|' '|' _)> */
+		nil,
+		/* 74 COMMA <- <(',' _)> */
+		nil,
+		/* 75 _ <- <Whitespace*> */
+		func() bool {
+			{
+				position335 := position
+			l336:
+				{
+					position337, tokenIndex337 := position, tokenIndex
+					{
+						position338 := position
+						{
+							switch buffer[position] {
+							case '\t':
+								if buffer[position] != rune('\t') {
+									goto l337
+								}
+								position++
+								break
+							case ' ':
+								if buffer[position] != rune(' ') {
+									goto l337
+								}
+								position++
+								break
+							default:
+								{
+									position340 := position
+									{
+										position341, tokenIndex341 := position, tokenIndex
+										if buffer[position] != rune('\r') {
+											goto l342
+										}
+										position++
+										if buffer[position] != rune('\n') {
+											goto l342
+										}
+										position++
+										goto l341
+									l342:
+										position, tokenIndex = position341, tokenIndex341
+										if buffer[position] != rune('\n') {
+											goto l343
+										}
+										position++
+										goto l341
+									l343:
+										position, tokenIndex = position341, tokenIndex341
+										if buffer[position] != rune('\r') {
+											goto l337
+										}
+										position++
+									}
+								l341:
+									add(ruleEOL, position340)
+								}
+								break
 							}
-							position++
-							if buffer[position] != rune('o') {
-								goto l307
+						}
+
+						add(ruleWhitespace, position338)
+					}
+					goto l336
+				l337:
+					position, tokenIndex = position337, tokenIndex337
+				}
+				add(rule_, position335)
+			}
+			return true
+		},
+		/* 76 Whitespace <- <((&('\t') '\t') | (&(' ') ' ') | (&('\n' | '\r') EOL))> */
+		nil,
+		/* 77 EOL <- <(('\r' '\n') / '\n' / '\r')> */
+		nil,
+		/* 78 EOF <- <!.> */
+		nil,
+		/* 79 Pipeline <- <(PIPE (StatsCommand / SortCommand / LimitCommand / FieldsCommand))> */
+		func() bool {
+			position352, tokenIndex352 := position, tokenIndex
+			{
+				position353 := position
+				if !_rules[rulePIPE]() {
+					goto l352
+				}
+				{
+					position354, tokenIndex354 := position, tokenIndex
+					if !_rules[ruleStatsCommand]() {
+						goto l355
+					}
+					goto l354
+				l355:
+					position, tokenIndex = position354, tokenIndex354
+					if !_rules[ruleSortCommand]() {
+						goto l356
+					}
+					goto l354
+				l356:
+					position, tokenIndex = position354, tokenIndex354
+					if !_rules[ruleLimitCommand]() {
+						goto l357
+					}
+					goto l354
+				l357:
+					position, tokenIndex = position354, tokenIndex354
+					if !_rules[ruleFieldsCommand]() {
+						goto l352
+					}
+				}
+			l354:
+				add(rulePipeline, position353)
+			}
+			return true
+		l352:
+			position, tokenIndex = position352, tokenIndex352
+			return false
+		},
+		/* 80 StatsCommand <- <(STATS Action32 Aggregate (COMMA Aggregate)* (BY GroupField (COMMA GroupField)*)?)> */
+		func() bool {
+			position358, tokenIndex358 := position, tokenIndex
+			{
+				position359 := position
+				if !_rules[ruleSTATS]() {
+					goto l358
+				}
+				{
+					add(ruleAction32, position)
+				}
+				if !_rules[ruleAggregate]() {
+					goto l358
+				}
+			l360:
+				{
+					position361, tokenIndex361 := position, tokenIndex
+					{
+						position362 := position
+						if buffer[position] != rune(',') {
+							goto l361
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l361
+						}
+						add(ruleCOMMA, position362)
+					}
+					if !_rules[ruleAggregate]() {
+						goto l361
+					}
+					goto l360
+				l361:
+					position, tokenIndex = position361, tokenIndex361
+				}
+				{
+					position363, tokenIndex363 := position, tokenIndex
+					if !_rules[ruleBY]() {
+						goto l363
+					}
+					if !_rules[ruleGroupField]() {
+						goto l363
+					}
+				l365:
+					{
+						position366, tokenIndex366 := position, tokenIndex
+						{
+							position367 := position
+							if buffer[position] != rune(',') {
+								goto l366
 							}
 							position++
-							if buffer[position] != rune('d') {
-								goto l307
+							if !_rules[rule_]() {
+								goto l366
 							}
-							position++
-							if buffer[position] != rune('u') {
-								goto l307
+							add(ruleCOMMA, position367)
+						}
+						if !_rules[ruleGroupField]() {
+							goto l366
+						}
+						goto l365
+					l366:
+						position, tokenIndex = position366, tokenIndex366
+					}
+					goto l364
+				l363:
+					position, tokenIndex = position363, tokenIndex363
+				}
+			l364:
+				add(ruleStatsCommand, position359)
+			}
+			return true
+		l358:
+			position, tokenIndex = position358, tokenIndex358
+			return false
+		},
+		/* 81 Aggregate <- <(Function LPAR (FieldName Action33)? RPAR Action34)> */
+		func() bool {
+			position368, tokenIndex368 := position, tokenIndex
+			{
+				position369 := position
+				if !_rules[ruleFunction]() {
+					goto l368
+				}
+				if !_rules[ruleLPAR]() {
+					goto l368
+				}
+				{
+					position370, tokenIndex370 := position, tokenIndex
+					if !_rules[ruleFieldName]() {
+						goto l370
+					}
+					{
+						add(ruleAction33, position)
+					}
+					goto l371
+				l370:
+					position, tokenIndex = position370, tokenIndex370
+				}
+			l371:
+				if !_rules[ruleRPAR]() {
+					goto l368
+				}
+				{
+					add(ruleAction34, position)
+				}
+				add(ruleAggregate, position369)
+			}
+			return true
+		l368:
+			position, tokenIndex = position368, tokenIndex368
+			return false
+		},
+		/* 82 Function <- <(<(('c' 'o' 'u' 'n' 't') / ('s' 'u' 'm') / ('a' 'v' 'g') / ('m' 'i' 'n') / ('m' 'a' 'x'))> !IdChar _ Action35)> */
+		func() bool {
+			position372, tokenIndex372 := position, tokenIndex
+			{
+				position373 := position
+				{
+					position374 := position
+					{
+						position375, tokenIndex375 := position, tokenIndex
+						if buffer[position] != rune('c') {
+							goto l376
+						}
+						position++
+						if buffer[position] != rune('o') {
+							goto l376
+						}
+						position++
+						if buffer[position] != rune('u') {
+							goto l376
+						}
+						position++
+						if buffer[position] != rune('n') {
+							goto l376
+						}
+						position++
+						if buffer[position] != rune('t') {
+							goto l376
+						}
+						position++
+						goto l375
+					l376:
+						position, tokenIndex = position375, tokenIndex375
+						if buffer[position] != rune('s') {
+							goto l377
+						}
+						position++
+						if buffer[position] != rune('u') {
+							goto l377
+						}
+						position++
+						if buffer[position] != rune('m') {
+							goto l377
+						}
+						position++
+						goto l375
+					l377:
+						position, tokenIndex = position375, tokenIndex375
+						if buffer[position] != rune('a') {
+							goto l378
+						}
+						position++
+						if buffer[position] != rune('v') {
+							goto l378
+						}
+						position++
+						if buffer[position] != rune('g') {
+							goto l378
+						}
+						position++
+						goto l375
+					l378:
+						position, tokenIndex = position375, tokenIndex375
+						if buffer[position] != rune('m') {
+							goto l379
+						}
+						position++
+						if buffer[position] != rune('i') {
+							goto l379
+						}
+						position++
+						if buffer[position] != rune('n') {
+							goto l379
+						}
+						position++
+						goto l375
+					l379:
+						position, tokenIndex = position375, tokenIndex375
+						if buffer[position] != rune('m') {
+							goto l372
+						}
+						position++
+						if buffer[position] != rune('a') {
+							goto l372
+						}
+						position++
+						if buffer[position] != rune('x') {
+							goto l372
+						}
+						position++
+					}
+				l375:
+					add(rulePegText, position374)
+				}
+				{
+					position380, tokenIndex380 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l380
+					}
+					goto l372
+				l380:
+					position, tokenIndex = position380, tokenIndex380
+				}
+				if !_rules[rule_]() {
+					goto l372
+				}
+				{
+					add(ruleAction35, position)
+				}
+				add(ruleFunction, position373)
+			}
+			return true
+		l372:
+			position, tokenIndex = position372, tokenIndex372
+			return false
+		},
+		/* 83 GroupField <- <(FieldName Action36)> */
+		func() bool {
+			position381, tokenIndex381 := position, tokenIndex
+			{
+				position382 := position
+				if !_rules[ruleFieldName]() {
+					goto l381
+				}
+				{
+					add(ruleAction36, position)
+				}
+				add(ruleGroupField, position382)
+			}
+			return true
+		l381:
+			position, tokenIndex = position381, tokenIndex381
+			return false
+		},
+		/* 84 SortCommand <- <(SORT FieldName Action37 (ASC / (DESC Action38))?)> */
+		func() bool {
+			position383, tokenIndex383 := position, tokenIndex
+			{
+				position384 := position
+				if !_rules[ruleSORT]() {
+					goto l383
+				}
+				if !_rules[ruleFieldName]() {
+					goto l383
+				}
+				{
+					add(ruleAction37, position)
+				}
+				{
+					position385, tokenIndex385 := position, tokenIndex
+					{
+						position387, tokenIndex387 := position, tokenIndex
+						if !_rules[ruleASC]() {
+							goto l388
+						}
+						goto l387
+					l388:
+						position, tokenIndex = position387, tokenIndex387
+						if !_rules[ruleDESC]() {
+							goto l385
+						}
+						{
+							add(ruleAction38, position)
+						}
+					}
+				l387:
+					goto l386
+				l385:
+					position, tokenIndex = position385, tokenIndex385
+				}
+			l386:
+				add(ruleSortCommand, position384)
+			}
+			return true
+		l383:
+			position, tokenIndex = position383, tokenIndex383
+			return false
+		},
+		/* 85 LimitCommand <- <(LIMIT <[0-9]+> _ Action39)> */
+		func() bool {
+			position389, tokenIndex389 := position, tokenIndex
+			{
+				position390 := position
+				if !_rules[ruleLIMIT]() {
+					goto l389
+				}
+				{
+					position391 := position
+					if c := buffer[position]; c < rune('0') || c > rune('9') {
+						goto l389
+					}
+					position++
+				l392:
+					{
+						position393, tokenIndex393 := position, tokenIndex
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l393
+						}
+						position++
+						goto l392
+					l393:
+						position, tokenIndex = position393, tokenIndex393
+					}
+					add(rulePegText, position391)
+				}
+				if !_rules[rule_]() {
+					goto l389
+				}
+				{
+					add(ruleAction39, position)
+				}
+				add(ruleLimitCommand, position390)
+			}
+			return true
+		l389:
+			position, tokenIndex = position389, tokenIndex389
+			return false
+		},
+		/* 86 FieldsCommand <- <(FIELDS Action40 FieldName Action41 (COMMA FieldName Action42)*)> */
+		func() bool {
+			position394, tokenIndex394 := position, tokenIndex
+			{
+				position395 := position
+				if !_rules[ruleFIELDS]() {
+					goto l394
+				}
+				{
+					add(ruleAction40, position)
+				}
+				if !_rules[ruleFieldName]() {
+					goto l394
+				}
+				{
+					add(ruleAction41, position)
+				}
+			l396:
+				{
+					position397, tokenIndex397 := position, tokenIndex
+					{
+						position398 := position
+						if buffer[position] != rune(',') {
+							goto l397
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l397
+						}
+						add(ruleCOMMA, position398)
+					}
+					if !_rules[ruleFieldName]() {
+						goto l397
+					}
+					{
+						add(ruleAction42, position)
+					}
+					goto l396
+				l397:
+					position, tokenIndex = position397, tokenIndex397
+				}
+				add(ruleFieldsCommand, position395)
+			}
+			return true
+		l394:
+			position, tokenIndex = position394, tokenIndex394
+			return false
+		},
+		/* 87 FieldName <- <(<(IdCharNoDigit IdChar* ('.' IdCharNoDigit IdChar*)*)> _)> */
+		func() bool {
+			position399, tokenIndex399 := position, tokenIndex
+			{
+				position400 := position
+				{
+					position401 := position
+					{
+						position402 := position
+						{
+							switch buffer[position] {
+							case '_':
+								if buffer[position] != rune('_') {
+									goto l399
+								}
+								position++
+								break
+							case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z':
+								if c := buffer[position]; c < rune('A') || c > rune('Z') {
+									goto l399
+								}
+								position++
+								break
+							default:
+								if c := buffer[position]; c < rune('a') || c > rune('z') {
+									goto l399
+								}
+								position++
+								break
 							}
-							position++
-							if buffer[position] != rune('c') {
-								goto l307
+						}
+
+						add(ruleIdCharNoDigit, position402)
+					}
+				l403:
+					{
+						position404, tokenIndex404 := position, tokenIndex
+						if !_rules[ruleIdChar]() {
+							goto l404
+						}
+						goto l403
+					l404:
+						position, tokenIndex = position404, tokenIndex404
+					}
+				l405:
+					{
+						position406, tokenIndex406 := position, tokenIndex
+						if buffer[position] != rune('.') {
+							goto l406
+						}
+						position++
+						{
+							position407 := position
+							{
+								switch buffer[position] {
+								case '_':
+									if buffer[position] != rune('_') {
+										goto l406
+									}
+									position++
+									break
+								case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z':
+									if c := buffer[position]; c < rune('A') || c > rune('Z') {
+										goto l406
+									}
+									position++
+									break
+								default:
+									if c := buffer[position]; c < rune('a') || c > rune('z') {
+										goto l406
+									}
+									position++
+									break
+								}
 							}
-							position++
-							if buffer[position] != rune('t') {
-								goto l307
+
+							add(ruleIdCharNoDigit, position407)
+						}
+					l408:
+						{
+							position409, tokenIndex409 := position, tokenIndex
+							if !_rules[ruleIdChar]() {
+								goto l409
 							}
-							position++
-							if buffer[position] != rune('i') {
-								goto l307
+							goto l408
+						l409:
+							position, tokenIndex = position409, tokenIndex409
+						}
+						goto l405
+					l406:
+						position, tokenIndex = position406, tokenIndex406
+					}
+					add(rulePegText, position401)
+				}
+				if !_rules[rule_]() {
+					goto l399
+				}
+				add(ruleFieldName, position400)
+			}
+			return true
+		l399:
+			position, tokenIndex = position399, tokenIndex399
+			return false
+		},
+		/* 88 PIPE <- <('|' !'|' _)> */
+		func() bool {
+			position410, tokenIndex410 := position, tokenIndex
+			{
+				position411 := position
+				if buffer[position] != rune('|') {
+					goto l410
+				}
+				position++
+				{
+					position412, tokenIndex412 := position, tokenIndex
+					if buffer[position] != rune('|') {
+						goto l412
+					}
+					position++
+					goto l410
+				l412:
+					position, tokenIndex = position412, tokenIndex412
+				}
+				if !_rules[rule_]() {
+					goto l410
+				}
+				add(rulePIPE, position411)
+			}
+			return true
+		l410:
+			position, tokenIndex = position410, tokenIndex410
+			return false
+		},
+		/* 89 STATS <- <('s' 't' 'a' 't' 's' !IdChar _)> */
+		func() bool {
+			position413, tokenIndex413 := position, tokenIndex
+			{
+				position414 := position
+				if buffer[position] != rune('s') {
+					goto l413
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l413
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l413
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l413
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l413
+				}
+				position++
+				{
+					position415, tokenIndex415 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l415
+					}
+					goto l413
+				l415:
+					position, tokenIndex = position415, tokenIndex415
+				}
+				if !_rules[rule_]() {
+					goto l413
+				}
+				add(ruleSTATS, position414)
+			}
+			return true
+		l413:
+			position, tokenIndex = position413, tokenIndex413
+			return false
+		},
+		/* 90 BY <- <('b' 'y' !IdChar _)> */
+		func() bool {
+			position416, tokenIndex416 := position, tokenIndex
+			{
+				position417 := position
+				if buffer[position] != rune('b') {
+					goto l416
+				}
+				position++
+				if buffer[position] != rune('y') {
+					goto l416
+				}
+				position++
+				{
+					position418, tokenIndex418 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l418
+					}
+					goto l416
+				l418:
+					position, tokenIndex = position418, tokenIndex418
+				}
+				if !_rules[rule_]() {
+					goto l416
+				}
+				add(ruleBY, position417)
+			}
+			return true
+		l416:
+			position, tokenIndex = position416, tokenIndex416
+			return false
+		},
+		/* 91 SORT <- <('s' 'o' 'r' 't' !IdChar _)> */
+		func() bool {
+			position419, tokenIndex419 := position, tokenIndex
+			{
+				position420 := position
+				if buffer[position] != rune('s') {
+					goto l419
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l419
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l419
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l419
+				}
+				position++
+				{
+					position421, tokenIndex421 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l421
+					}
+					goto l419
+				l421:
+					position, tokenIndex = position421, tokenIndex421
+				}
+				if !_rules[rule_]() {
+					goto l419
+				}
+				add(ruleSORT, position420)
+			}
+			return true
+		l419:
+			position, tokenIndex = position419, tokenIndex419
+			return false
+		},
+		/* 92 ASC <- <('a' 's' 'c' !IdChar _)> */
+		func() bool {
+			position422, tokenIndex422 := position, tokenIndex
+			{
+				position423 := position
+				if buffer[position] != rune('a') {
+					goto l422
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l422
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l422
+				}
+				position++
+				{
+					position424, tokenIndex424 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l424
+					}
+					goto l422
+				l424:
+					position, tokenIndex = position424, tokenIndex424
+				}
+				if !_rules[rule_]() {
+					goto l422
+				}
+				add(ruleASC, position423)
+			}
+			return true
+		l422:
+			position, tokenIndex = position422, tokenIndex422
+			return false
+		},
+		/* 93 DESC <- <('d' 'e' 's' 'c' !IdChar _)> */
+		func() bool {
+			position425, tokenIndex425 := position, tokenIndex
+			{
+				position426 := position
+				if buffer[position] != rune('d') {
+					goto l425
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l425
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l425
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l425
+				}
+				position++
+				{
+					position427, tokenIndex427 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l427
+					}
+					goto l425
+				l427:
+					position, tokenIndex = position427, tokenIndex427
+				}
+				if !_rules[rule_]() {
+					goto l425
+				}
+				add(ruleDESC, position426)
+			}
+			return true
+		l425:
+			position, tokenIndex = position425, tokenIndex425
+			return false
+		},
+		/* 94 LIMIT <- <('l' 'i' 'm' 'i' 't' !IdChar _)> */
+		func() bool {
+			position428, tokenIndex428 := position, tokenIndex
+			{
+				position429 := position
+				if buffer[position] != rune('l') {
+					goto l428
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l428
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l428
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l428
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l428
+				}
+				position++
+				{
+					position430, tokenIndex430 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l430
+					}
+					goto l428
+				l430:
+					position, tokenIndex = position430, tokenIndex430
+				}
+				if !_rules[rule_]() {
+					goto l428
+				}
+				add(ruleLIMIT, position429)
+			}
+			return true
+		l428:
+			position, tokenIndex = position428, tokenIndex428
+			return false
+		},
+		/* 95 FIELDS <- <('f' 'i' 'e' 'l' 'd' 's' !IdChar _)> */
+		func() bool {
+			position431, tokenIndex431 := position, tokenIndex
+			{
+				position432 := position
+				if buffer[position] != rune('f') {
+					goto l431
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l431
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l431
+				}
+				position++
+				if buffer[position] != rune('l') {
+					goto l431
+				}
+				position++
+				if buffer[position] != rune('d') {
+					goto l431
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l431
+				}
+				position++
+				{
+					position433, tokenIndex433 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l433
+					}
+					goto l431
+				l433:
+					position, tokenIndex = position433, tokenIndex433
+				}
+				if !_rules[rule_]() {
+					goto l431
+				}
+				add(ruleFIELDS, position432)
+			}
+			return true
+		l431:
+			position, tokenIndex = position431, tokenIndex431
+			return false
+		},
+		/* 96 Regexp <- <('/' <RegexpChar*> '/' _)> */
+		func() bool {
+			position479, tokenIndex479 := position, tokenIndex
+			{
+				position480 := position
+				if buffer[position] != rune('/') {
+					goto l479
+				}
+				position++
+				{
+					position481 := position
+				l482:
+					{
+						position483, tokenIndex483 := position, tokenIndex
+						if !_rules[ruleRegexpChar]() {
+							goto l483
+						}
+						goto l482
+					l483:
+						position, tokenIndex = position483, tokenIndex483
+					}
+					add(rulePegText, position481)
+				}
+				if buffer[position] != rune('/') {
+					goto l479
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l479
+				}
+				add(ruleRegexp, position480)
+			}
+			return true
+		l479:
+			position, tokenIndex = position479, tokenIndex479
+			return false
+		},
+		/* 97 RegexpChar <- <(('\\' .) / (!('/' / '\n' / '\\') .))> */
+		func() bool {
+			position484, tokenIndex484 := position, tokenIndex
+			{
+				position485 := position
+				{
+					position486, tokenIndex486 := position, tokenIndex
+					if buffer[position] != rune('\\') {
+						goto l487
+					}
+					position++
+					if !matchDot() {
+						goto l487
+					}
+					goto l486
+				l487:
+					position, tokenIndex = position486, tokenIndex486
+					{
+						position488, tokenIndex488 := position, tokenIndex
+						{
+							position489, tokenIndex489 := position, tokenIndex
+							if buffer[position] != rune('/') {
+								goto l490
 							}
 							position++
-							if buffer[position] != rune('o') {
-								goto l307
+							goto l489
+						l490:
+							position, tokenIndex = position489, tokenIndex489
+							if buffer[position] != rune('\n') {
+								goto l491
 							}
 							position++
-							if buffer[position] != rune('n') {
-								goto l307
+							goto l489
+						l491:
+							position, tokenIndex = position489, tokenIndex489
+							if buffer[position] != rune('\\') {
+								goto l488
 							}
 							position++
-							break
 						}
+					l489:
+						goto l484
+					l488:
+						position, tokenIndex = position488, tokenIndex488
 					}
-
+					if !matchDot() {
+						goto l484
+					}
+				}
+			l486:
+				add(ruleRegexpChar, position485)
+			}
+			return true
+		l484:
+			position, tokenIndex = position484, tokenIndex484
+			return false
+		},
+		/* 98 MATCHES <- <('m' 'a' 't' 'c' 'h' 'e' 's' !IdChar _)> */
+		func() bool {
+			position492, tokenIndex492 := position, tokenIndex
+			{
+				position493 := position
+				if buffer[position] != rune('m') {
+					goto l492
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l492
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l492
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l492
+				}
+				position++
+				if buffer[position] != rune('h') {
+					goto l492
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l492
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l492
 				}
-			l309:
+				position++
 				{
-					position315, tokenIndex315 := position, tokenIndex
+					position494, tokenIndex494 := position, tokenIndex
 					if !_rules[ruleIdChar]() {
-						goto l315
+						goto l494
 					}
-					goto l307
-				l315:
-					position, tokenIndex = position315, tokenIndex315
+					goto l492
+				l494:
+					position, tokenIndex = position494, tokenIndex494
+				}
+				if !_rules[rule_]() {
+					goto l492
 				}
-				add(ruleKeyword, position308)
+				add(ruleMATCHES, position493)
 			}
 			return true
-		l307:
-			position, tokenIndex = position307, tokenIndex307
+		l492:
+			position, tokenIndex = position492, tokenIndex492
 			return false
 		},
-		/* 59 EQ <- <('=' _)> */
-		nil,
-		/* 60 LBRK <- <('[' _)> */
-		nil,
-		/* 61 RBRK <- <(']' _)> */
-		nil,
-		/* 62 LPAR <- <('(' _)> */
+		/* 99 IEQ <- <('i' 'e' 'q' !IdChar _)> */
 		func() bool {
-			position319, tokenIndex319 := position, tokenIndex
+			position495, tokenIndex495 := position, tokenIndex
 			{
-				position320 := position
-				if buffer[position] != rune('(') {
-					goto l319
+				position496 := position
+				if buffer[position] != rune('i') {
+					goto l495
 				}
 				position++
+				if buffer[position] != rune('e') {
+					goto l495
+				}
+				position++
+				if buffer[position] != rune('q') {
+					goto l495
+				}
+				position++
+				{
+					position497, tokenIndex497 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l497
+					}
+					goto l495
+				l497:
+					position, tokenIndex = position497, tokenIndex497
+				}
 				if !_rules[rule_]() {
-					goto l319
+					goto l495
 				}
-				add(ruleLPAR, position320)
+				add(ruleIEQ, position496)
 			}
 			return true
-		l319:
-			position, tokenIndex = position319, tokenIndex319
+		l495:
+			position, tokenIndex = position495, tokenIndex495
 			return false
 		},
-		/* 63 RPAR <- <(')' _)> */
+		/* 100 INE <- <('i' 'n' 'e' !IdChar _)> */
 		func() bool {
-			position321, tokenIndex321 := position, tokenIndex
+			position498, tokenIndex498 := position, tokenIndex
 			{
-				position322 := position
-				if buffer[position] != rune(')') {
-					goto l321
+				position499 := position
+				if buffer[position] != rune('i') {
+					goto l498
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l498
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l498
 				}
 				position++
+				{
+					position500, tokenIndex500 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l500
+					}
+					goto l498
+				l500:
+					position, tokenIndex = position500, tokenIndex500
+				}
 				if !_rules[rule_]() {
-					goto l321
+					goto l498
 				}
-				add(ruleRPAR, position322)
+				add(ruleINE, position499)
 			}
 			return true
-		l321:
-			position, tokenIndex = position321, tokenIndex321
+		l498:
+			position, tokenIndex = position498, tokenIndex498
 			return false
 		},
-		/* 64 DOT <- <('.' _)> */
+		/* 101 ICONTAINS <- <('i' 'c' 'o' 'n' 't' 'a' 'i' 'n' 's' !IdChar _)> */
+		func() bool {
+			position501, tokenIndex501 := position, tokenIndex
+			{
+				position502 := position
+				if buffer[position] != rune('i') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('c') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('o') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l501
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l501
+				}
+				position++
+				{
+					position503, tokenIndex503 := position, tokenIndex
+					if !_rules[ruleIdChar]() {
+						goto l503
+					}
+					goto l501
+				l503:
+					position, tokenIndex = position503, tokenIndex503
+				}
+				if !_rules[rule_]() {
+					goto l501
+				}
+				add(ruleICONTAINS, position502)
+			}
+			return true
+		l501:
+			position, tokenIndex = position501, tokenIndex501
+			return false
+		},
+		/* 102 MATCH <- <('=' '~' _)> */
+		func() bool {
+			position504, tokenIndex504 := position, tokenIndex
+			{
+				position505 := position
+				if buffer[position] != rune('=') {
+					goto l504
+				}
+				position++
+				if buffer[position] != rune('~') {
+					goto l504
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l504
+				}
+				add(ruleMATCH, position505)
+			}
+			return true
+		l504:
+			position, tokenIndex = position504, tokenIndex504
+			return false
+		},
+		/* 103 NOMATCH <- <('!' '~' _)> */
+		func() bool {
+			position506, tokenIndex506 := position, tokenIndex
+			{
+				position507 := position
+				if buffer[position] != rune('!') {
+					goto l506
+				}
+				position++
+				if buffer[position] != rune('~') {
+					goto l506
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l506
+				}
+				add(ruleNOMATCH, position507)
+			}
+			return true
+		l506:
+			position, tokenIndex = position506, tokenIndex506
+			return false
+		},
+		/* 105 Action0 <- <{ p.AddNumber(text) }> */
 		nil,
-		/* 65 BANG <- <('!' !'=' _)> */
+		/* 106 Action1 <- <{ p.AddNumber("")   }> */
 		nil,
-		/* 66 LT <- <('<' !'=' _)> */
+		/* 107 Action2 <- <{ p.AddLevel(text)  }> */
 		nil,
-		/* 67 GT <- <('>' !'=' _)> */
+		/* 108 Action3 <- <{ p.AddStage(text)  }> */
 		nil,
-		/* 68 LE <- <('<' '=' _)> */
+		/* 109 Action4 <- <{ p.AddField(text)  }> */
 		nil,
-		/* 69 EQEQ <- <('=' '=' _)> */
+		/* 110 Action5 <- <{ p.AddString(text) }> */
 		nil,
-		/* 70 GE <- <('>' '=' _)> */
+		/* 111 Action6 <- <{ p.AddString(text) }> */
 		nil,
-		/* 71 NE <- <('!' '=' _)> */
+		/* 112 Action7 <- <{ p.AddExpr()       }> */
 		nil,
-		/* 72 ANDAND <- <('&' '&' _)> */
+		/* 113 Action8 <- <{ p.AddTupleValue() }> */
 		nil,
-		/* 73 OROR <- <('|' '|' _)> */
+		/* 114 Action9 <- <{ p.AddTupleValue() }> */
 		nil,
-		/* 74 COMMA <- <(',' _)> */
+		/* 115 Action10 <- <{ p.AddTuple() }> */
 		nil,
-		/* 75 _ <- <Whitespace*> */
-		func() bool {
-			{
-				position335 := position
-			l336:
-				{
-					position337, tokenIndex337 := position, tokenIndex
-					{
-						position338 := position
-						{
-							switch buffer[position] {
-							case '\t':
-								if buffer[position] != rune('\t') {
-									goto l337
-								}
-								position++
-								break
-							case ' ':
-								if buffer[position] != rune(' ') {
-									goto l337
-								}
-								position++
-								break
-							default:
-								{
-									position340 := position
-									{
-										position341, tokenIndex341 := position, tokenIndex
-										if buffer[position] != rune('\r') {
-											goto l342
-										}
-										position++
-										if buffer[position] != rune('\n') {
-											goto l342
-										}
-										position++
-										goto l341
-									l342:
-										position, tokenIndex = position341, tokenIndex341
-										if buffer[position] != rune('\n') {
-											goto l343
-										}
-										position++
-										goto l341
-									l343:
-										position, tokenIndex = position341, tokenIndex341
-										if buffer[position] != rune('\r') {
-											goto l337
-										}
-										position++
-									}
-								l341:
-									add(ruleEOL, position340)
-								}
-								break
-							}
-						}
-
-						add(ruleWhitespace, position338)
-					}
-					goto l336
-				l337:
-					position, tokenIndex = position337, tokenIndex337
-				}
-				add(rule_, position335)
-			}
-			return true
-		},
-		/* 76 Whitespace <- <((&('\t') '\t') | (&(' ') ' ') | (&('\n' | '\r') EOL))> */
+		/* 116 Action11 <- <{ p.AddBinary(ast.IN) }> */
 		nil,
-		/* 77 EOL <- <(('\r' '\n') / '\n' / '\r')> */
+		/* 117 Action12 <- <{ p.AddTuple() }> */
 		nil,
-		/* 78 EOF <- <!.> */
+		/* 118 Action13 <- <{ p.AddBinary(ast.IN); p.AddUnary(ast.LNOT) }> */
+		nil,
+		/* 119 Action14 <- <{ p.AddMember(text)    }> */
+		nil,
+		/* 120 Action15 <- <{ p.AddSubscript(text) }> */
+		nil,
+		/* 121 Action16 <- <{ p.AddUnary(ast.NOT) }> */
 		nil,
-		/* 80 Action0 <- <{ p.AddNumber(text) }> */
+		/* 122 Action17 <- <{ p.AddBinary(ast.GE) }> */
 		nil,
-		/* 81 Action1 <- <{ p.AddNumber("")   }> */
+		/* 123 Action18 <- <{ p.AddBinary(ast.GT) }> */
 		nil,
-		/* 82 Action2 <- <{ p.AddLevel(text)  }> */
+		/* 124 Action19 <- <{ p.AddBinary(ast.LE) }> */
 		nil,
-		/* 83 Action3 <- <{ p.AddStage(text)  }> */
+		/* 125 Action20 <- <{ p.AddBinary(ast.LT) }> */
 		nil,
-		/* 84 Action4 <- <{ p.AddField(text)  }> */
+		/* 126 Action21 <- <{ p.AddBinary(ast.EQ)                   }> */
 		nil,
-		/* 85 Action5 <- <{ p.AddString(text) }> */
+		/* 127 Action22 <- <{ p.AddBinary(ast.NE)                   }> */
 		nil,
-		/* 86 Action6 <- <{ p.AddString(text) }> */
+		/* 128 Action23 <- <{ p.AddBinary(ast.EQ)                   }> */
 		nil,
-		/* 87 Action7 <- <{ p.AddExpr()       }> */
+		/* 129 Action24 <- <{ p.AddBinaryContains(ast.EQ)           }> */
 		nil,
-		/* 88 Action8 <- <{ p.AddTupleValue() }> */
+		/* 130 Action25 <- <{ p.AddBinary(ast.AND) }> */
 		nil,
-		/* 89 Action9 <- <{ p.AddTupleValue() }> */
+		/* 131 Action26 <- <{ p.AddBinary(ast.AND) }> */
 		nil,
-		/* 90 Action10 <- <{ p.AddTuple() }> */
+		/* 132 Action27 <- <{ p.AddBinary(ast.AND) }> */
 		nil,
-		/* 91 Action11 <- <{ p.AddBinary(ast.IN) }> */
+		/* 133 Action28 <- <{ p.AddBinary(ast.OR) }> */
 		nil,
-		/* 92 Action12 <- <{ p.AddTuple() }> */
+		/* 134 Action29 <- <{ p.AddBinary(ast.OR) }> */
 		nil,
-		/* 93 Action13 <- <{ p.AddBinary(ast.IN); p.AddUnary(ast.LNOT) }> */
+		/* 135 Action30 <- <{ p.AddUnary(ast.LNOT) }> */
 		nil,
-		/* 94 Action14 <- <{ p.AddMember(text)    }> */
 		nil,
-		/* 95 Action15 <- <{ p.AddSubscript(text) }> */
+		/* 137 Action31 <- <{ p.SetNumber(text) }> */
 		nil,
-		/* 96 Action16 <- <{ p.AddUnary(ast.NOT) }> */
+		/* 138 Action32 <- <{ p.AddStats() }> */
 		nil,
-		/* 97 Action17 <- <{ p.AddBinary(ast.GE) }> */
+		/* 139 Action33 <- <{ p.SetArgument(text) }> */
 		nil,
-		/* 98 Action18 <- <{ p.AddBinary(ast.GT) }> */
+		/* 140 Action34 <- <{ p.AddAggregate() }> */
 		nil,
-		/* 99 Action19 <- <{ p.AddBinary(ast.LE) }> */
+		/* 141 Action35 <- <{ p.SetFunction(text) }> */
 		nil,
-		/* 100 Action20 <- <{ p.AddBinary(ast.LT) }> */
+		/* 142 Action36 <- <{ p.AddGroup(text) }> */
 		nil,
-		/* 101 Action21 <- <{ p.AddBinary(ast.EQ)   }> */
+		/* 143 Action37 <- <{ p.AddSort(text) }> */
 		nil,
-		/* 102 Action22 <- <{ p.AddBinary(ast.NE)   }> */
+		/* 144 Action38 <- <{ p.SetDescending() }> */
 		nil,
-		/* 103 Action23 <- <{ p.AddBinary(ast.EQ)   }> */
+		/* 145 Action39 <- <{ p.AddLimit(text) }> */
 		nil,
-		/* 104 Action24 <- <{ p.AddBinaryContains() }> */
+		/* 146 Action40 <- <{ p.AddFields() }> */
 		nil,
-		/* 105 Action25 <- <{ p.AddBinary(ast.AND) }> */
+		/* 147 Action41 <- <{ p.AddProjection(text) }> */
 		nil,
-		/* 106 Action26 <- <{ p.AddBinary(ast.AND) }> */
+		/* 148 Action42 <- <{ p.AddProjection(text) }> */
 		nil,
-		/* 107 Action27 <- <{ p.AddBinary(ast.AND) }> */
+		/* 149 Action43 <- <{ p.AddRegexp(text, begin, ast.MATCH)   }> */
 		nil,
-		/* 108 Action28 <- <{ p.AddBinary(ast.OR) }> */
+		/* 150 Action44 <- <{ p.AddRegexp(text, begin, ast.NOMATCH) }> */
 		nil,
-		/* 109 Action29 <- <{ p.AddBinary(ast.OR) }> */
+		/* 151 Action45 <- <{ p.AddRegexp(text, begin, ast.MATCH)   }> */
 		nil,
-		/* 110 Action30 <- <{ p.AddUnary(ast.LNOT) }> */
+		/* 152 Action46 <- <{ p.AddBinary(ast.IEQ)                  }> */
 		nil,
+		/* 153 Action47 <- <{ p.AddBinary(ast.INE)                  }> */
 		nil,
-		/* 112 Action31 <- <{ p.SetNumber(text) }> */
+		/* 154 Action48 <- <{ p.AddBinaryContains(ast.IEQ)          }> */
 		nil,
 	}
 	p.rules = _rules
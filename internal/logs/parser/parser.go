@@ -6,7 +6,9 @@
 package parser
 
 import (
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/apex/up/internal/logs/parser/ast"
 )
@@ -17,11 +19,25 @@ func Parse(s string) (ast.Node, error) {
 	p.Init()
 
 	if err := p.Parse(); err != nil {
+		return nil, syntaxError(s, int(err.(*parseError).max.end))
+	}
+
+	if err := p.checkKeywords(); err != nil {
 		return nil, err
 	}
 
 	p.Execute()
-	n := ast.Root{Node: p.stack[0]}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	n := ast.Root{Stages: p.stages}
+
+	if len(p.stack) > 0 {
+		n.Node = p.stack[0]
+	}
+
 	return n, nil
 }
 
@@ -133,14 +149,45 @@ func (p *parser) AddStage(stage string) {
 }
 
 // AddBinaryContains node.
-func (p *parser) AddBinaryContains() {
+func (p *parser) AddBinaryContains(op ast.Op) {
 	p.push(ast.Binary{
-		Op:    ast.EQ,
+		Op:    op,
 		Right: ast.Contains{Node: p.pop()},
 		Left:  p.pop(),
 	})
 }
 
+// checkKeywords returns an error for identifiers and unquoted strings
+// which are keywords in the wrong case, such as "AND", as these would
+// otherwise silently match a field or message.
+func (p *parser) checkKeywords() error {
+	for _, t := range p.Tokens() {
+		if t.pegRule != ruleId && t.pegRule != ruleUnquotedString {
+			continue
+		}
+
+		s := strings.TrimSpace(string(p.buffer[t.begin:t.end]))
+		if k := strings.ToLower(s); keywords[k] && k != s {
+			return keywordError(p.Buffer, int(t.begin), k)
+		}
+	}
+
+	return nil
+}
+
+// AddRegexp node, matching the popped node against regular expression s at offset.
+func (p *parser) AddRegexp(s string, offset int, op ast.Op) {
+	if _, err := regexp.Compile(s); err != nil && p.err == nil {
+		p.err = regexpError(p.Buffer, offset, err)
+	}
+
+	p.push(ast.Binary{
+		Op:    op,
+		Right: ast.Regexp(s),
+		Left:  p.pop(),
+	})
+}
+
 // AddUnary node.
 func (p *parser) AddUnary(op ast.Op) {
 	p.push(ast.Unary{
@@ -148,3 +195,79 @@ func (p *parser) AddUnary(op ast.Op) {
 		Right: p.pop(),
 	})
 }
+
+// stage returns the last pipeline stage.
+func (p *parser) stage() ast.Node {
+	if len(p.stages) == 0 {
+		panic("stage: no stages")
+	}
+
+	return p.stages[len(p.stages)-1]
+}
+
+// setStage replaces the last pipeline stage.
+func (p *parser) setStage(n ast.Node) {
+	p.stages[len(p.stages)-1] = n
+}
+
+// AddStats stage.
+func (p *parser) AddStats() {
+	p.stages = append(p.stages, ast.Stats{})
+}
+
+// SetFunction text.
+func (p *parser) SetFunction(s string) {
+	p.function = s
+}
+
+// SetArgument text.
+func (p *parser) SetArgument(s string) {
+	p.argument = s
+}
+
+// AddAggregate to the stats stage.
+func (p *parser) AddAggregate() {
+	n := p.stage().(ast.Stats)
+	n.Aggregates = append(n.Aggregates, ast.Aggregate{
+		Func:  p.function,
+		Field: p.argument,
+	})
+	p.setStage(n)
+	p.function, p.argument = "", ""
+}
+
+// AddGroup to the stats stage.
+func (p *parser) AddGroup(s string) {
+	n := p.stage().(ast.Stats)
+	n.By = append(n.By, s)
+	p.setStage(n)
+}
+
+// AddSort stage.
+func (p *parser) AddSort(s string) {
+	p.stages = append(p.stages, ast.Sort{Field: s})
+}
+
+// SetDescending sort order.
+func (p *parser) SetDescending() {
+	n := p.stage().(ast.Sort)
+	n.Desc = true
+	p.setStage(n)
+}
+
+// AddLimit stage.
+func (p *parser) AddLimit(s string) {
+	n, _ := strconv.Atoi(s)
+	p.stages = append(p.stages, ast.Limit(n))
+}
+
+// AddFields stage.
+func (p *parser) AddFields() {
+	p.stages = append(p.stages, ast.Fields{})
+}
+
+// AddProjection to the fields stage.
+func (p *parser) AddProjection(s string) {
+	n := p.stage().(ast.Fields)
+	p.setStage(append(n, s))
+}
@@ -2,13 +2,13 @@ package parser
 
 import (
 	"testing"
+
+	"github.com/apex/up/internal/logs/parser/ast"
 )
 
 // TODO: precedence...
 // TODO: byte size literals
 // TODO: support literals: `method = GET`, `ip = 70.*` etc
-// TODO: error tests
-// TODO: test error messages
 // TODO: add "starts with" / "ends with" to compliment "contains"?
 // TODO: document best practices for logging json from an app
 
@@ -113,3 +113,154 @@ func BenchmarkParse(b *testing.B) {
 		Parse(`user.name in ("Tobi", "Loki", "Jane")`)
 	}
 }
+
+var insightsCases = []struct {
+	Input  string
+	Output string
+}{
+	{`status >= 500`, `fields @timestamp, @message | filter fields.status >= 500`},
+	{`error`, `fields @timestamp, @message | filter (level = "error")`},
+	{`production method in ("GET", "HEAD")`, `fields @timestamp, @message | filter fields.stage = "production" and fields.method in ["GET", "HEAD"]`},
+	{`ip = "207.*" and !user.admin`, `fields @timestamp, @message | filter fields.ip like /^207\..*$/ and not (ispresent(fields.user.admin))`},
+	{`path != "/static/*"`, `fields @timestamp, @message | filter fields.path not like /^\/static\/.*$/`},
+	{`user.email contains "@apex.sh"`, `fields @timestamp, @message | filter fields.user.email like /^.*@apex\.sh.*$/`},
+	{`"User Login"`, `fields @timestamp, @message | filter message = "User Login"`},
+	{`duration > 1s | sort duration desc | limit 20`, `fields @timestamp, @message | filter fields.duration > 1000 | sort fields.duration desc | limit 20`},
+	{`status >= 500 | stats count() by path`, `filter fields.status >= 500 | stats count(*) as count by fields.path`},
+	{`| stats count(), avg(duration) by method, path | sort count desc | limit 5`, `stats count(*) as count, avg(fields.duration) as avg_duration by fields.method, fields.path | sort count desc | limit 5`},
+	{`| stats max(user.age) | sort max_user_age`, `stats max(fields.user.age) as max_user_age | sort max_user_age asc`},
+	{`| stats count() by path | sort path asc`, `stats count(*) as count by fields.path | sort fields.path asc`},
+	{`warn | fields id, status, timestamp`, `filter (level = "warn") | fields fields.id, fields.status, @timestamp`},
+	{`path matches /^\/api\/v[0-9]+/`, `fields @timestamp, @message | filter fields.path like /^\/api\/v[0-9]+/`},
+	{`user.email =~ /@apex\.sh$/`, `fields @timestamp, @message | filter fields.user.email like /@apex\.sh$/`},
+	{`path !~ /^\/static/`, `fields @timestamp, @message | filter fields.path not like /^\/static/`},
+	{`method ieq "get"`, `fields @timestamp, @message | filter fields.method like /(?i)^get$/`},
+	{`user.name ine "tobi*"`, `fields @timestamp, @message | filter fields.user.name not like /(?i)^tobi.*$/`},
+	{`message icontains "login"`, `fields @timestamp, @message | filter message like /(?i)^.*login.*$/`},
+	{`status ieq 200`, `fields @timestamp, @message | filter fields.status = 200`},
+}
+
+func TestParse_insights(t *testing.T) {
+	for _, c := range insightsCases {
+		t.Logf("parsing %q", c.Input)
+		n, err := Parse(c.Input)
+
+		if err != nil {
+			t.Errorf("error parsing %q: %s", c.Input, err)
+			continue
+		}
+
+		s := n.(ast.Root).Insights()
+		if s != c.Output {
+			t.Errorf("\n\ntext: %s\nwant: %s\n got: %s\n\n", c.Input, c.Output, s)
+		}
+	}
+}
+
+var patternCases = []struct {
+	Input  string
+	Output string
+	Exact  bool
+}{
+	{`status >= 500`, `{ $.fields.status >= 500 }`, true},
+	{`path matches /^\/api\//`, `{ $.fields.path = "/api/*" }`, true},
+	{`path =~ /login/`, `{ $.fields.path = "*login*" }`, true},
+	{`path !~ /^\/static\/.*\.css$/`, ``, false},
+	{`path !~ /\.css$/`, `{ $.fields.path != "*.css" }`, true},
+	{`path matches /^\/users\/[0-9]+$/ status = 200`, `{ $.fields.status = 200 }`, false},
+	{`status = 200 path matches /(?i)login/`, `{ $.fields.status = 200 }`, false},
+	{`method ieq "get" or status = 500`, ``, false},
+	{`not method ieq "get"`, ``, false},
+	{`ip ieq "207.*" production`, `{ $.fields.ip = "207.*" && $.fields.stage = "production" }`, true},
+	{`user.name icontains "tobi" (warn or error)`, `{ (($.level = "warn") || ($.level = "error")) }`, false},
+}
+
+func TestParse_pattern(t *testing.T) {
+	for _, c := range patternCases {
+		t.Logf("parsing %q", c.Input)
+		n, err := Parse(c.Input)
+
+		if err != nil {
+			t.Errorf("error parsing %q: %s", c.Input, err)
+			continue
+		}
+
+		s, exact := n.(ast.Root).Pattern()
+		if s != c.Output || exact != c.Exact {
+			t.Errorf("\n\ntext: %s\nwant: %s (exact %t)\n got: %s (exact %t)\n\n", c.Input, c.Output, c.Exact, s, exact)
+		}
+	}
+}
+
+func TestParse_regexpErrors(t *testing.T) {
+	for _, s := range []string{
+		`path matches /[a-z/`,
+		`path =~ /(foo/`,
+		`path matches "foo"`,
+		`path =~ /foo`,
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestParse_pipelineErrors(t *testing.T) {
+	for _, s := range []string{
+		`status = 200 | stats`,
+		`| stats count() by`,
+		`| sort`,
+		`| limit x`,
+		`| fields`,
+		`| explode`,
+		`status = 200 |`,
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestParse_syntaxErrors(t *testing.T) {
+	cases := []struct {
+		Input string
+		Error string
+	}{
+		{
+			`status >= 500 and (method = GET`,
+			"unexpected end of query at column 32, expected \")\", \"and\", \"or\", an operator, or a value\n\n  status >= 500 and (method = GET\n                                 ^",
+		},
+		{
+			`user.email = tj@apex.sh`,
+			"unexpected \"@apex.sh\" at column 16, expected end of query, \"and\", \"or\", \"|\", an operator, or a value\n\n  user.email = tj@apex.sh\n                 ^\n\nDid you mean \"tj@apex.sh\"? Strings containing special characters must be quoted.",
+		},
+		{
+			`status === 200`,
+			"unexpected \"=\" at column 10, expected a value\n\n  status === 200\n           ^\n\nDid you mean \"=\" instead of \"===\"?",
+		},
+		{
+			`"foo`,
+			"unexpected end of query at column 5\n\n  \"foo\n      ^\n\nStrings must be terminated with a closing quote.",
+		},
+		{
+			"status = 200\n  AND method = \"GET\"",
+			"unexpected \"AND\" at line 2 column 3\n\n    AND method = \"GET\"\n    ^\n\nDid you mean \"and\"? Keywords are lowercase.",
+		},
+		{
+			`path =~ /(foo/`,
+			"invalid regular expression, missing closing ) at column 10\n\n  path =~ /(foo/\n           ^",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := Parse(c.Input)
+		if err == nil {
+			t.Errorf("expected error parsing %q", c.Input)
+			continue
+		}
+
+		if err.Error() != c.Error {
+			t.Errorf("\n\ntext: %s\nwant:\n%s\n\n got:\n%s\n\n", c.Input, c.Error, err)
+		}
+	}
+}
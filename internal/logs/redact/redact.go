@@ -0,0 +1,174 @@
+// Package redact provides scrubbing of sensitive
+// values such as tokens or emails from logs.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/config"
+)
+
+// mask replacing redacted values.
+const mask = "[REDACTED]"
+
+// randomKey used to hash values when no key is configured, shared
+// so that the values of request logs and app logs correlate.
+var randomKey = newKey()
+
+// Redactor redacts log fields and messages. A nil
+// redactor is valid, and leaves values untouched.
+type Redactor struct {
+	fields   map[string]bool
+	query    map[string]bool
+	patterns []*regexp.Regexp
+	hash     bool
+	key      []byte
+}
+
+// New redactor from config, nil is returned when there is nothing to redact.
+func New(c config.Redact) (*Redactor, error) {
+	if len(c.Fields) == 0 && len(c.Query) == 0 && len(c.Patterns) == 0 {
+		return nil, nil
+	}
+
+	r := &Redactor{
+		fields: make(map[string]bool),
+		query:  make(map[string]bool),
+		hash:   c.Mode == "hash",
+		key:    randomKey,
+	}
+
+	if c.Key != "" {
+		r.key = []byte(c.Key)
+	}
+
+	for _, s := range c.Fields {
+		r.fields[strings.ToLower(s)] = true
+	}
+
+	for _, s := range c.Query {
+		r.query[strings.ToLower(s)] = true
+	}
+
+	for _, s := range c.Patterns {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "compiling %q", s)
+		}
+
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// Value returns the redacted value of s. Hashes are an HMAC-SHA256 of
+// s, so they are only stable for values hashed with the same key.
+func (r *Redactor) Value(s string) string {
+	if !r.hash {
+		return mask
+	}
+
+	h := hmac.New(sha256.New, r.key)
+	h.Write([]byte(s))
+	return "hmac:" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// String returns s with matches of the patterns redacted.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+
+	for _, re := range r.patterns {
+		s = re.ReplaceAllStringFunc(s, r.Value)
+	}
+
+	return s
+}
+
+// Query returns the query string with the parameters redacted.
+func (r *Redactor) Query(v url.Values) url.Values {
+	if r == nil {
+		return v
+	}
+
+	q := make(url.Values, len(v))
+
+	for k, values := range v {
+		for _, s := range values {
+			if r.query[strings.ToLower(k)] {
+				s = r.Value(s)
+			} else {
+				s = r.String(s)
+			}
+
+			q.Add(k, s)
+		}
+	}
+
+	return q
+}
+
+// Fields returns a copy of the fields redacted, including nested fields.
+func (r *Redactor) Fields(f log.Fields) log.Fields {
+	if r == nil {
+		return f
+	}
+
+	m := make(log.Fields, len(f))
+
+	for k, v := range f {
+		m[k] = r.field(k, v)
+	}
+
+	return m
+}
+
+// field returns the redacted value of field k.
+func (r *Redactor) field(k string, v interface{}) interface{} {
+	if r.fields[strings.ToLower(k)] {
+		if s, ok := v.(string); ok {
+			return r.Value(s)
+		}
+
+		return mask
+	}
+
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		return map[string]interface{}(r.Fields(v))
+	case log.Fields:
+		return r.Fields(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = r.field("", item)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// newKey returns a random key.
+func newKey() []byte {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.Wrap(err, "generating redaction key"))
+	}
+
+	return b
+}
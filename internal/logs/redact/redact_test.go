@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+
+	"github.com/apex/up/config"
+)
+
+func TestNew(t *testing.T) {
+	r, err := New(config.Redact{})
+	assert.NoError(t, err, "new")
+	assert.Nil(t, r, "nothing to redact")
+
+	assert.Equal(t, "tobi@apex.sh", r.String("tobi@apex.sh"))
+	assert.Equal(t, log.Fields{"email": "tobi@apex.sh"}, r.Fields(log.Fields{"email": "tobi@apex.sh"}))
+}
+
+func TestRedactor_mask(t *testing.T) {
+	r, err := New(config.Redact{
+		Fields:   []string{"email", "IP"},
+		Query:    []string{"token"},
+		Patterns: []string{`\b\d{4}-\d{4}-\d{4}-\d{4}\b`},
+		Mode:     "mask",
+	})
+	assert.NoError(t, err, "new")
+
+	t.Run("string", func(t *testing.T) {
+		assert.Equal(t, "paid with [REDACTED] today", r.String("paid with 4242-4242-4242-4242 today"))
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		f := log.Fields{
+			"email": "tobi@apex.sh",
+			"ip":    "127.0.0.1",
+			"card":  "4242-4242-4242-4242",
+			"user": map[string]interface{}{
+				"Email": "loki@apex.sh",
+				"id":    float64(5),
+			},
+			"count": 3,
+		}
+
+		assert.Equal(t, log.Fields{
+			"email": "[REDACTED]",
+			"ip":    "[REDACTED]",
+			"card":  "[REDACTED]",
+			"user": map[string]interface{}{
+				"Email": "[REDACTED]",
+				"id":    float64(5),
+			},
+			"count": 3,
+		}, r.Fields(f))
+
+		assert.Equal(t, "tobi@apex.sh", f["email"], "original is untouched")
+	})
+
+	t.Run("query", func(t *testing.T) {
+		q := url.Values{
+			"token": {"secret"},
+			"page":  {"2"},
+		}
+
+		assert.Equal(t, "page=2&token=%5BREDACTED%5D", r.Query(q).Encode())
+	})
+}
+
+func TestRedactor_hash(t *testing.T) {
+	t.Run("key", func(t *testing.T) {
+		r, err := New(config.Redact{
+			Fields: []string{"email"},
+			Mode:   "hash",
+			Key:    "secret",
+		})
+		assert.NoError(t, err, "new")
+
+		f := r.Fields(log.Fields{"email": "tobi@apex.sh"})
+		assert.Equal(t, "hmac:5412c6a009ec27ae766b227fc26ba466", f["email"])
+		assert.Equal(t, f, r.Fields(log.Fields{"email": "tobi@apex.sh"}), "stable")
+	})
+
+	t.Run("random key", func(t *testing.T) {
+		a, err := New(config.Redact{Fields: []string{"email"}, Mode: "hash"})
+		assert.NoError(t, err, "new")
+
+		b, err := New(config.Redact{Fields: []string{"email"}, Mode: "hash", Key: "secret"})
+		assert.NoError(t, err, "new")
+
+		assert.Equal(t, a.Value("tobi@apex.sh"), a.Value("tobi@apex.sh"), "stable")
+		assert.NotEqual(t, a.Value("tobi@apex.sh"), b.Value("tobi@apex.sh"), "keyed")
+	})
+}
@@ -3,15 +3,23 @@ package text
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/dustin/go-humanize"
 
 	"github.com/apex/up/internal/colors"
+	"github.com/apex/up/internal/logs/access"
+	"github.com/apex/up/internal/logs/export"
+	"github.com/apex/up/internal/logs/group"
+	"github.com/apex/up/internal/logs/lambda"
 	"github.com/apex/up/internal/util"
 )
 
@@ -27,12 +35,13 @@ type colorFunc func(string) string
 
 // omit fields.
 var omit = map[string]bool{
-	"app":     true,
-	"stage":   true,
-	"region":  true,
-	"plugin":  true,
-	"commit":  true,
-	"version": true,
+	"app":       true,
+	"stage":     true,
+	"region":    true,
+	"plugin":    true,
+	"commit":    true,
+	"version":   true,
+	"lambda_id": true,
 }
 
 // Colors mapping.
@@ -53,18 +62,54 @@ var Strings = [...]string{
 	log.FatalLevel: "FATA",
 }
 
+// escape matches color and style escape sequences.
+var escape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// plain writer removing escape sequences.
+type plain struct {
+	io.Writer
+}
+
+// Write implementation.
+func (w plain) Write(b []byte) (int, error) {
+	_, err := w.Writer.Write(escape.ReplaceAll(b, emptyBytes))
+	return len(b), err
+}
+
+// Template returns a template for formatting entries such as
+// "{{.Timestamp}} {{.Fields.status}}", with the "field" function
+// looking up nested fields such as {{field "user.email" .}}, empty
+// when missing, and the "json" function formatting values as JSON.
+func Template(s string) (*template.Template, error) {
+	return template.New("format").Funcs(template.FuncMap{
+		"field": func(name string, e *log.Entry) string {
+			return export.String(export.Value(e, name))
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(s)
+}
+
 // Handler implementation.
 type Handler struct {
-	mu     sync.Mutex
-	Writer io.Writer
-	expand bool
-	layout string
+	mu       sync.Mutex
+	Writer   io.Writer
+	expand   bool
+	regions  bool
+	layout   string
+	color    bool
+	template *template.Template
+	columns  []string
+	widths   []int
 }
 
 // New handler.
 func New(w io.Writer) *Handler {
 	return &Handler{
 		Writer: w,
+		color:  true,
 	}
 }
 
@@ -74,9 +119,44 @@ func (h *Handler) WithExpandedFields(v bool) *Handler {
 	return h
 }
 
+// WithRegions sets whether the region of inline
+// logs is shown, for logs of multiple regions.
+func (h *Handler) WithRegions(v bool) *Handler {
+	h.regions = v
+	return h
+}
+
+// WithColors sets whether output is colored.
+func (h *Handler) WithColors(v bool) *Handler {
+	h.color = v
+	return h
+}
+
+// WithTemplate sets the template used to format
+// each entry, taking precedence over other layouts.
+func (h *Handler) WithTemplate(t *template.Template) *Handler {
+	h.template = t
+	return h
+}
+
+// WithColumns sets the columns output for each entry, such as
+// "timestamp", "level", "message" or a field such as "user.email",
+// aligned to the widest value seen so far.
+func (h *Handler) WithColumns(columns []string) *Handler {
+	h.columns = columns
+	h.widths = make([]int, len(columns))
+	return h
+}
+
 // HandleLog implements log.Handler.
 func (h *Handler) HandleLog(e *log.Entry) error {
+	e = parseAccess(e)
+
 	switch {
+	case h.template != nil:
+		return h.handleTemplate(e)
+	case len(h.columns) > 0:
+		return h.handleColumns(e)
 	case h.expand:
 		return h.handleExpanded(e)
 	default:
@@ -84,6 +164,137 @@ func (h *Handler) HandleLog(e *log.Entry) error {
 	}
 }
 
+// HandleRequest outputs the entries of a request as a block, with
+// its id and the duration, billed duration and memory used of its
+// invocation. Lambda's START, END and REPORT lines are omitted as
+// they are summarized by the block.
+func (h *Handler) HandleRequest(r *group.Request) error {
+	id := r.ID
+	if id == "" {
+		id = r.LambdaID
+	}
+
+	if id == "" {
+		for _, e := range r.Entries {
+			if err := h.HandleLog(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	h.mu.Lock()
+	fmt.Fprintf(h.out(), "\n  %s %s\n", bold(colors.Purple("REQUEST")), colors.Gray(id))
+	h.mu.Unlock()
+
+	for _, e := range r.Entries {
+		if lambda.Is(e) {
+			continue
+		}
+
+		if err := h.HandleLog(e); err != nil {
+			return err
+		}
+	}
+
+	var stats []string
+
+	if r.Duration > 0 {
+		stats = append(stats, stat("duration", value("duration", r.Duration)))
+	}
+
+	if r.BilledDuration > 0 {
+		stats = append(stats, stat("billed", value("duration", r.BilledDuration)))
+	}
+
+	if r.MemorySize > 0 {
+		stats = append(stats, stat("memory", fmt.Sprintf("%.0f MB of %.0f MB", r.MemoryUsed, r.MemorySize)))
+	}
+
+	if len(stats) > 0 {
+		h.mu.Lock()
+		fmt.Fprintf(h.out(), "  %s\n", strings.Join(stats, "  "))
+		h.mu.Unlock()
+	}
+
+	return nil
+}
+
+// stat returns a formatted summary statistic.
+func stat(name string, v interface{}) string {
+	return fmt.Sprintf("%s%s%v", colors.Gray(name), colors.Gray(": "), v)
+}
+
+// handleTemplate formats the entry with the template.
+func (h *Handler) handleTemplate(e *log.Entry) error {
+	var buf bytes.Buffer
+
+	if err := h.template.Execute(&buf, e); err != nil {
+		return err
+	}
+
+	if !bytes.HasSuffix(buf.Bytes(), newlineBytes) {
+		buf.Write(newlineBytes)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out().Write(buf.Bytes())
+	return err
+}
+
+// handleColumns outputs the values of columns.
+func (h *Handler) handleColumns(e *log.Entry) error {
+	var buf bytes.Buffer
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buf.WriteString(" ")
+
+	for i, name := range h.columns {
+		var s string
+		color := colorFunc(plainString)
+
+		switch name {
+		case "timestamp":
+			s = formatDate(e.Timestamp.Local())
+			color = colors.Gray
+		case "level":
+			s = Strings[e.Level]
+			color = func(s string) string { return bold(Colors[e.Level](s)) }
+		case "message":
+			s = e.Message
+			color = colors.Purple
+		default:
+			v := export.Value(e, name)
+			switch {
+			case v == nil:
+				s = "-"
+			case name == "size" || name == "duration":
+				s = fmt.Sprint(value(name, v))
+			default:
+				s = export.String(v)
+			}
+		}
+
+		if n := len(s); n > h.widths[i] {
+			h.widths[i] = n
+		}
+
+		buf.WriteString(" ")
+		buf.WriteString(color(s))
+
+		if i < len(h.columns)-1 {
+			buf.WriteString(strings.Repeat(" ", h.widths[i]-len(s)))
+		}
+	}
+
+	buf.Write(newlineBytes)
+	_, err := h.out().Write(buf.Bytes())
+	return err
+}
+
 // handleExpanded fields.
 func (h *Handler) handleExpanded(e *log.Entry) error {
 	color := Colors[e.Level]
@@ -94,7 +305,8 @@ func (h *Handler) handleExpanded(e *log.Entry) error {
 	defer h.mu.Unlock()
 
 	ts := formatDate(e.Timestamp.Local())
-	fmt.Fprintf(h.Writer, "  %s %s %s\n", colors.Gray(ts), bold(color(level)), colors.Purple(e.Message))
+	w := h.out()
+	fmt.Fprintf(w, "  %s %s %s\n", colors.Gray(ts), bold(color(level)), colors.Purple(e.Message))
 
 	for _, name := range names {
 		v := e.Fields.Get(name)
@@ -103,11 +315,11 @@ func (h *Handler) handleExpanded(e *log.Entry) error {
 			continue
 		}
 
-		fmt.Fprintf(h.Writer, "    %s%s%v\n", color(name), colors.Gray(": "), value(name, v))
+		fmt.Fprintf(w, "    %s%s%v\n", color(name), colors.Gray(": "), value(name, v))
 	}
 
 	if len(names) > 0 {
-		fmt.Fprintf(h.Writer, "\n")
+		fmt.Fprintf(w, "\n")
 	}
 
 	return nil
@@ -123,12 +335,18 @@ func (h *Handler) handleInline(e *log.Entry) error {
 	names := e.Fields.Names()
 	ts := formatDate(e.Timestamp.Local())
 
+	fmt.Fprintf(&buf, "  %s %s ", colors.Gray(ts), bold(color(level)))
+
+	if region, ok := e.Fields.Get("region").(string); ok && region != "" && h.regions {
+		fmt.Fprintf(&buf, "%s ", colors.Gray(region))
+	}
+
 	if stage, ok := e.Fields.Get("stage").(string); ok && stage != "" {
-		fmt.Fprintf(&buf, "  %s %s %s %s %s{{spacer}}", colors.Gray(ts), bold(color(level)), colors.Gray(stage), colors.Gray(version(e)), colors.Purple(e.Message))
-	} else {
-		fmt.Fprintf(&buf, "  %s %s %s{{spacer}}", colors.Gray(ts), bold(color(level)), colors.Purple(e.Message))
+		fmt.Fprintf(&buf, "%s %s ", colors.Gray(stage), colors.Gray(version(e)))
 	}
 
+	fmt.Fprintf(&buf, "%s{{spacer}}", colors.Purple(e.Message))
+
 	for _, name := range names {
 		if omit[name] {
 			continue
@@ -153,13 +371,39 @@ func (h *Handler) handleInline(e *log.Entry) error {
 	}
 
 	h.mu.Lock()
-	h.Writer.Write(b)
-	h.Writer.Write(newlineBytes)
+	h.out().Write(append(b, newlineBytes...))
 	h.mu.Unlock()
 
 	return nil
 }
 
+// out returns the writer for output, removing
+// escape sequences when colors are disabled.
+func (h *Handler) out() io.Writer {
+	if h.color {
+		return h.Writer
+	}
+
+	return plain{h.Writer}
+}
+
+// parseAccess returns a structured entry for Common or
+// Combined access log lines, or the entry unchanged.
+func parseAccess(e *log.Entry) *log.Entry {
+	a, ok := access.Parse(e.Message)
+	if !ok {
+		return e
+	}
+
+	for name, v := range e.Fields {
+		if _, ok := a.Fields[name]; !ok {
+			a.Fields[name] = v
+		}
+	}
+
+	return a
+}
+
 // value returns the formatted value.
 func value(name string, v interface{}) interface{} {
 	switch name {
@@ -193,6 +437,11 @@ func version(e *log.Entry) string {
 	return ""
 }
 
+// plainString returns s unchanged.
+func plainString(s string) string {
+	return s
+}
+
 // bold string.
 func bold(s string) string {
 	return fmt.Sprintf("\033[1m%s\033[0m", s)
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"github.com/apex/log"
+	"github.com/tj/assert"
+
+	"github.com/apex/up/internal/logs/group"
 )
 
 func init() {
@@ -27,3 +30,149 @@ func Test(t *testing.T) {
 
 	io.Copy(os.Stdout, &buf)
 }
+
+func TestHandler_access(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := New(&buf)
+	h.HandleLog(&log.Entry{
+		Level:   log.InfoLevel,
+		Message: `192.0.2.1 - - [16/Oct/2017:13:55:36 -0700] "GET /pets?page=2 HTTP/1.1" 404 12 "-" "curl"`,
+		Fields:  log.Fields{"plugin": "logs"},
+	})
+
+	s := buf.String()
+	assert.Contains(t, s, "WARN")
+	assert.Contains(t, s, "response")
+	assert.Contains(t, s, "method")
+	assert.Contains(t, s, "/pets")
+	assert.Contains(t, s, "user_agent")
+	assert.NotContains(t, s, "HTTP/1.1")
+}
+
+func TestHandler_regions(t *testing.T) {
+	e := &log.Entry{
+		Level:   log.InfoLevel,
+		Message: "hello",
+		Fields:  log.Fields{"region": "eu-west-1", "stage": "production"},
+	}
+
+	var a bytes.Buffer
+	New(&a).HandleLog(e)
+	assert.NotContains(t, a.String(), "eu-west-1")
+	assert.Contains(t, a.String(), "production")
+
+	var b bytes.Buffer
+	New(&b).WithRegions(true).HandleLog(e)
+	assert.Contains(t, b.String(), "eu-west-1")
+	assert.Contains(t, b.String(), "production")
+}
+
+func TestHandler_HandleRequest(t *testing.T) {
+	t.Run("request", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := New(&buf).HandleRequest(&group.Request{
+			ID:       "8ff53267",
+			LambdaID: "3f5c0a4e",
+			Entries: []*log.Entry{
+				{Level: log.InfoLevel, Message: "start", Fields: log.Fields{"lambda_id": "3f5c0a4e"}},
+				{Level: log.InfoLevel, Message: "request", Fields: log.Fields{"id": "8ff53267", "lambda_id": "3f5c0a4e", "path": "/users"}},
+				{Level: log.InfoLevel, Message: "user login", Fields: log.Fields{"id": "8ff53267"}},
+				{Level: log.InfoLevel, Message: "report", Fields: log.Fields{"lambda_id": "3f5c0a4e"}},
+			},
+			Duration:       1520,
+			BilledDuration: 1600,
+			MemoryUsed:     40,
+			MemorySize:     512,
+		})
+
+		assert.NoError(t, err, "handle")
+
+		s := buf.String()
+		assert.Contains(t, s, "REQUEST")
+		assert.Contains(t, s, "8ff53267")
+		assert.Contains(t, s, "/users")
+		assert.Contains(t, s, "user login")
+		assert.Contains(t, s, "1.52s")
+		assert.Contains(t, s, "1.6s")
+		assert.Contains(t, s, "40 MB of 512 MB")
+		assert.NotContains(t, s, "3f5c0a4e")
+		assert.NotContains(t, s, "report")
+	})
+
+	t.Run("entries", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := New(&buf).HandleRequest(&group.Request{
+			Entries: []*log.Entry{
+				{Level: log.InfoLevel, Message: "initializing"},
+			},
+		})
+
+		assert.NoError(t, err, "handle")
+		assert.Contains(t, buf.String(), "initializing")
+		assert.NotContains(t, buf.String(), "REQUEST")
+	})
+}
+
+func TestHandler_WithTemplate(t *testing.T) {
+	tmpl, err := Template(`{{.Level}} {{.Fields.status}} {{field "user.email" .}}{{field "user.name" .}} {{json .Fields.user}}`)
+	assert.NoError(t, err, "parse")
+
+	var buf bytes.Buffer
+	New(&buf).WithTemplate(tmpl).HandleLog(&log.Entry{
+		Level:   log.WarnLevel,
+		Message: "response",
+		Fields: log.Fields{
+			"status": 404,
+			"user":   map[string]interface{}{"email": "tj@apex.sh"},
+		},
+	})
+
+	assert.Equal(t, "warn 404 tj@apex.sh {\"email\":\"tj@apex.sh\"}\n", buf.String())
+
+	_, err = Template(`{{.Fields.status`)
+	assert.Error(t, err, "parse")
+}
+
+func TestHandler_WithColumns(t *testing.T) {
+	var buf bytes.Buffer
+	h := New(&buf).WithColumns([]string{"message", "status", "duration", "path"}).WithColors(false)
+
+	h.HandleLog(&log.Entry{
+		Level:   log.InfoLevel,
+		Message: "response",
+		Fields:  log.Fields{"status": 200, "duration": 1500, "path": "/"},
+	})
+
+	h.HandleLog(&log.Entry{
+		Level:   log.InfoLevel,
+		Message: "request",
+		Fields:  log.Fields{"path": "/users"},
+	})
+
+	assert.Equal(t, "  response 200 1.5s /\n  request  -   -    /users\n", buf.String())
+}
+
+func TestHandler_WithColors(t *testing.T) {
+	e := &log.Entry{
+		Level:   log.InfoLevel,
+		Message: "hello",
+		Fields:  log.Fields{"user": "tj"},
+	}
+
+	var a bytes.Buffer
+	New(&a).HandleLog(e)
+	assert.Contains(t, a.String(), "\x1b[")
+
+	var b bytes.Buffer
+	New(&b).WithColors(false).HandleLog(e)
+	assert.NotContains(t, b.String(), "\x1b[")
+	assert.Contains(t, b.String(), "INFO hello: user=tj")
+
+	var c bytes.Buffer
+	New(&c).WithColors(false).WithExpandedFields(true).HandleLog(e)
+	assert.NotContains(t, c.String(), "\x1b[")
+	assert.Contains(t, c.String(), "user: tj")
+}
@@ -0,0 +1,112 @@
+package writer
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+)
+
+// levelPattern maps lines matching a pattern to a level.
+type levelPattern struct {
+	pattern *regexp.Regexp
+	level   log.Level
+}
+
+// Levels detects the level of plain text lines.
+type Levels []levelPattern
+
+// CompileLevels returns levels from a map of level names to patterns,
+// which take precedence over the built-in markers, with more severe
+// levels checked first.
+func CompileLevels(m map[string][]string) (Levels, error) {
+	var levels Levels
+
+	for name, patterns := range m {
+		level, err := log.ParseLevel(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "level %q", name)
+		}
+
+		for _, s := range patterns {
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, errors.Wrapf(err, "level %q pattern %q", name, s)
+			}
+
+			levels = append(levels, levelPattern{
+				pattern: re,
+				level:   level,
+			})
+		}
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		a, b := levels[i], levels[j]
+
+		if a.level != b.level {
+			return a.level > b.level
+		}
+
+		return a.pattern.String() < b.pattern.String()
+	})
+
+	return levels, nil
+}
+
+// marker regexp of a leading level such as "WARN", "[error]" or "Info:".
+var marker = regexp.MustCompile(`(?i)^\[?(trace|debug|info|notice|warn|warning|error|err|fatal|critical|crit|panic)\]?(?:[\s:]|$)`)
+
+// glog regexp of the leading level and date, such as "E1016 14:00:00".
+var glog = regexp.MustCompile(`^([IWEF])\d{4} \d\d:\d\d:\d\d`)
+
+// syslog regexp of the leading priority, such as "<3>".
+var syslog = regexp.MustCompile(`^<([0-7])>\s*`)
+
+// glog levels.
+var glogLevels = map[string]log.Level{
+	"I": log.InfoLevel,
+	"W": log.WarnLevel,
+	"E": log.ErrorLevel,
+	"F": log.FatalLevel,
+}
+
+// syslog priorities.
+var syslogLevels = []log.Level{
+	log.FatalLevel, // emerg
+	log.FatalLevel, // alert
+	log.FatalLevel, // crit
+	log.ErrorLevel, // err
+	log.WarnLevel,  // warning
+	log.InfoLevel,  // notice
+	log.InfoLevel,  // info
+	log.DebugLevel, // debug
+}
+
+// Detect returns the level of the line and its message, which
+// omits syslog priorities as they are not meant to be displayed.
+func (l Levels) Detect(s string) (log.Level, string, bool) {
+	for _, p := range l {
+		if p.pattern.MatchString(s) {
+			return p.level, s, true
+		}
+	}
+
+	if m := syslog.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return syslogLevels[n], s[len(m[0]):], true
+	}
+
+	if m := glog.FindStringSubmatch(s); m != nil {
+		return glogLevels[m[1]], s, true
+	}
+
+	if m := marker.FindStringSubmatch(s); m != nil {
+		level, ok := parseLevel(m[1])
+		return level, s, ok
+	}
+
+	return 0, s, false
+}
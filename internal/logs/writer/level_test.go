@@ -0,0 +1,72 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+func TestLevels_Detect(t *testing.T) {
+	levels, err := CompileLevels(map[string][]string{
+		"warn":  {"DeprecationWarning"},
+		"debug": {`^\[verbose\]`},
+	})
+	assert.NoError(t, err, "compile")
+
+	cases := []struct {
+		line  string
+		level log.Level
+		msg   string
+		ok    bool
+	}{
+		{"WARN disk almost full", log.WarnLevel, "WARN disk almost full", true},
+		{"[error] connection refused", log.ErrorLevel, "[error] connection refused", true},
+		{"Info: listening on 3000", log.InfoLevel, "Info: listening on 3000", true},
+		{"DEBUG", log.DebugLevel, "DEBUG", true},
+		{"E1016 14:00:00.000000 1 main.go:10] boom", log.ErrorLevel, "E1016 14:00:00.000000 1 main.go:10] boom", true},
+		{"<3>something failed", log.ErrorLevel, "something failed", true},
+		{"<7> details", log.DebugLevel, "details", true},
+		{"<0>panic", log.FatalLevel, "panic", true},
+		{"(node:1) DeprecationWarning: Buffer() is deprecated", log.WarnLevel, "(node:1) DeprecationWarning: Buffer() is deprecated", true},
+		{"[verbose] cache miss", log.DebugLevel, "[verbose] cache miss", true},
+		{"Errors are values", 0, "Errors are values", false},
+		{"GET /", 0, "GET /", false},
+		{"Information", 0, "Information", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.line, func(t *testing.T) {
+			level, msg, ok := levels.Detect(c.line)
+			assert.Equal(t, c.ok, ok, "ok")
+			assert.Equal(t, c.msg, msg, "message")
+			if ok {
+				assert.Equal(t, c.level, level, "level")
+			}
+		})
+	}
+}
+
+func TestCompileLevels(t *testing.T) {
+	t.Run("precedence", func(t *testing.T) {
+		levels, err := CompileLevels(map[string][]string{
+			"info":  {"request"},
+			"error": {"failed"},
+		})
+		assert.NoError(t, err, "compile")
+
+		level, _, ok := levels.Detect("request failed")
+		assert.True(t, ok, "ok")
+		assert.Equal(t, log.ErrorLevel, level)
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		_, err := CompileLevels(map[string][]string{"loud": {"x"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := CompileLevels(map[string][]string{"warn": {"("}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,280 @@
+package writer
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/util"
+)
+
+// Parser parses a line of output into a log entry.
+type Parser interface {
+	Parse(s string) (*log.Entry, bool)
+}
+
+// ParserFunc is a function parsing a line of output,
+// returning false when the line is not recognized.
+type ParserFunc func(s string) (*log.Entry, bool)
+
+// Parse implementation.
+func (f ParserFunc) Parse(s string) (*log.Entry, bool) {
+	return f(s)
+}
+
+// Parsers tries each parser in turn.
+type Parsers []Parser
+
+// Parse implementation.
+func (p Parsers) Parse(s string) (*log.Entry, bool) {
+	for _, parser := range p {
+		if e, ok := parser.Parse(s); ok {
+			return e, true
+		}
+	}
+
+	return nil, false
+}
+
+// Parsers available.
+var (
+	// JSON parses apex/log style JSON.
+	JSON = ParserFunc(parseJSON)
+
+	// Logfmt parses any logfmt line, such as `level=info msg="hello" user=42`.
+	Logfmt = ParserFunc(func(s string) (*log.Entry, bool) {
+		return parseLogfmt(s, false)
+	})
+
+	// Rails parses the Ruby logger format used by Rails.
+	Rails = ParserFunc(parseRails)
+
+	// Gunicorn parses the Gunicorn error log format.
+	Gunicorn = ParserFunc(parseGunicorn)
+
+	// SpringBoot parses the Spring Boot console format.
+	SpringBoot = ParserFunc(parseSpringBoot)
+
+	// Auto detects any of the formats supported, logfmt
+	// lines must have a level or message to be recognized.
+	Auto = Parsers{
+		JSON,
+		ParserFunc(func(s string) (*log.Entry, bool) {
+			return parseLogfmt(s, true)
+		}),
+		Rails,
+		Gunicorn,
+		SpringBoot,
+	}
+)
+
+// ParserFor returns the parser for the given format.
+func ParserFor(format string) (Parser, error) {
+	switch format {
+	case "auto":
+		return Auto, nil
+	case "json":
+		return JSON, nil
+	case "logfmt":
+		return Logfmt, nil
+	default:
+		return nil, errors.Errorf("unknown format %q", format)
+	}
+}
+
+// parseJSON parses a json log.
+func parseJSON(s string) (*log.Entry, bool) {
+	if !util.IsJSONLog(s) {
+		return nil, false
+	}
+
+	var e log.Entry
+
+	if err := json.Unmarshal([]byte(s), &e); err != nil {
+		return nil, false
+	}
+
+	return &e, true
+}
+
+// logfmt pair regexp.
+var pair = regexp.MustCompile(`^([^\s="]+)(?:=("(?:[^"\\]|\\.)*"|[^\s"]*))?(?:\s+|$)`)
+
+// parseLogfmt parses a logfmt line. When strict the line must contain
+// a level or message, so that plain text is not mistaken for logfmt.
+func parseLogfmt(s string, strict bool) (*log.Entry, bool) {
+	e := &log.Entry{
+		Level:  log.InfoLevel,
+		Fields: log.Fields{},
+	}
+
+	var pairs, known int
+	rest := strings.TrimSpace(s)
+
+	for rest != "" {
+		m := pair.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, false
+		}
+
+		rest = rest[len(m[0]):]
+		key, val := m[1], m[2]
+
+		if strings.HasPrefix(val, `"`) {
+			v, err := strconv.Unquote(val)
+			if err != nil {
+				return nil, false
+			}
+			val = v
+		}
+
+		if strings.Contains(m[0], "=") {
+			pairs++
+		}
+
+		switch key {
+		case "level", "lvl", "severity":
+			l, ok := parseLevel(val)
+			if !ok {
+				return nil, false
+			}
+			e.Level = l
+			known++
+		case "msg", "message":
+			e.Message = val
+			known++
+		case "time", "ts", "timestamp":
+			if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+				e.Timestamp = t
+				continue
+			}
+			e.Fields[key] = val
+		default:
+			if !strings.Contains(m[0], "=") {
+				e.Fields[key] = true
+				continue
+			}
+			e.Fields[key] = parseValue(val)
+		}
+	}
+
+	if pairs == 0 || (strict && known == 0) {
+		return nil, false
+	}
+
+	return e, true
+}
+
+// parseValue returns a number or boolean when possible, as these
+// are queryable as such once logged to CloudWatch as JSON.
+func parseValue(s string) interface{} {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n
+	}
+
+	if b, err := strconv.ParseBool(s); err == nil && strings.ToLower(s) == s {
+		return b
+	}
+
+	return s
+}
+
+// Rails logger regexp, for example:
+//
+//	I, [2018-03-14T10:00:00.123456 #1234]  INFO -- : Started GET "/"
+var rails = regexp.MustCompile(`^[DIWEFAU], \[(\S+) #(\d+)\]\s+(\w+) -- ([^:]*): (.*)$`)
+
+// parseRails parses a Rails log line.
+func parseRails(s string) (*log.Entry, bool) {
+	m := rails.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+
+	return entry(m[3], m[5], "2006-01-02T15:04:05.999999", m[1], log.Fields{
+		"pid":      parseValue(m[2]),
+		"progname": strings.TrimSpace(m[4]),
+	})
+}
+
+// Gunicorn logger regexp, for example:
+//
+//	[2018-03-14 10:00:00 +0000] [1234] [INFO] Booting worker with pid: 1235
+var gunicorn = regexp.MustCompile(`^\[(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d [-+]\d{4})\] \[(\d+)\] \[(\w+)\] (.*)$`)
+
+// parseGunicorn parses a Gunicorn log line.
+func parseGunicorn(s string) (*log.Entry, bool) {
+	m := gunicorn.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+
+	return entry(m[3], m[4], "2006-01-02 15:04:05 -0700", m[1], log.Fields{
+		"pid": parseValue(m[2]),
+	})
+}
+
+// Spring Boot logger regexp, for example:
+//
+//	2018-03-14 10:00:00.123  INFO 1234 --- [main] c.e.demo.DemoApplication : Started
+var springBoot = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{3})\s+(\w+) (\d+) --- \[\s*([^\]]*)\] (\S+)\s*: (.*)$`)
+
+// parseSpringBoot parses a Spring Boot log line.
+func parseSpringBoot(s string) (*log.Entry, bool) {
+	m := springBoot.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+
+	return entry(m[2], m[6], "2006-01-02 15:04:05.000", m[1], log.Fields{
+		"pid":    parseValue(m[3]),
+		"thread": m[4],
+		"logger": m[5],
+	})
+}
+
+// entry returns a log entry from the parts of a framework log line.
+func entry(level, msg, layout, timestamp string, fields log.Fields) (*log.Entry, bool) {
+	l, ok := parseLevel(level)
+	if !ok {
+		return nil, false
+	}
+
+	for k, v := range fields {
+		if v == "" {
+			delete(fields, k)
+		}
+	}
+
+	t, _ := time.Parse(layout, timestamp)
+
+	return &log.Entry{
+		Level:     l,
+		Message:   msg,
+		Timestamp: t,
+		Fields:    fields,
+	}, true
+}
+
+// parseLevel returns the log level from the many names used by frameworks.
+func parseLevel(s string) (log.Level, bool) {
+	switch strings.ToLower(s) {
+	case "trace", "debug":
+		return log.DebugLevel, true
+	case "info", "notice":
+		return log.InfoLevel, true
+	case "warn", "warning":
+		return log.WarnLevel, true
+	case "error", "err":
+		return log.ErrorLevel, true
+	case "fatal", "critical", "crit", "panic", "alert", "emerg", "unknown", "any":
+		return log.FatalLevel, true
+	default:
+		return 0, false
+	}
+}
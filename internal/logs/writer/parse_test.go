@@ -0,0 +1,95 @@
+package writer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+func TestLogfmt(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		e, ok := Logfmt.Parse(`level=warn msg="user signed in" user=42 admin=true name=tobi ts=2018-03-14T10:00:00Z debug`)
+		assert.True(t, ok, "ok")
+		assert.Equal(t, log.WarnLevel, e.Level)
+		assert.Equal(t, "user signed in", e.Message)
+		assert.Equal(t, time.Date(2018, 3, 14, 10, 0, 0, 0, time.UTC), e.Timestamp)
+		assert.Equal(t, log.Fields{
+			"user":  float64(42),
+			"admin": true,
+			"name":  "tobi",
+			"debug": true,
+		}, e.Fields)
+	})
+
+	t.Run("escaped quotes", func(t *testing.T) {
+		e, ok := Logfmt.Parse(`msg="say \"hello\"" path=/`)
+		assert.True(t, ok, "ok")
+		assert.Equal(t, `say "hello"`, e.Message)
+		assert.Equal(t, log.Fields{"path": "/"}, e.Fields)
+	})
+
+	t.Run("without level or message", func(t *testing.T) {
+		e, ok := Logfmt.Parse(`user=42`)
+		assert.True(t, ok, "ok")
+		assert.Equal(t, log.InfoLevel, e.Level)
+
+		_, ok = Auto.Parse(`user=42`)
+		assert.False(t, ok, "auto")
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		_, ok := Logfmt.Parse(`GET /`)
+		assert.False(t, ok, "words")
+
+		_, ok = Logfmt.Parse(`Started GET "/" for 127.0.0.1`)
+		assert.False(t, ok, "quotes")
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		_, ok := Logfmt.Parse(`level=loud msg=hello`)
+		assert.False(t, ok, "ok")
+	})
+}
+
+func TestRails(t *testing.T) {
+	e, ok := Auto.Parse(`E, [2018-03-14T10:00:00.123456 #1234] ERROR -- : Something broke`)
+	assert.True(t, ok, "ok")
+	assert.Equal(t, log.ErrorLevel, e.Level)
+	assert.Equal(t, "Something broke", e.Message)
+	assert.Equal(t, time.Date(2018, 3, 14, 10, 0, 0, 123456000, time.UTC), e.Timestamp)
+	assert.Equal(t, log.Fields{"pid": float64(1234)}, e.Fields)
+}
+
+func TestGunicorn(t *testing.T) {
+	e, ok := Auto.Parse(`[2018-03-14 10:00:00 +0000] [1234] [WARNING] Worker timeout`)
+	assert.True(t, ok, "ok")
+	assert.Equal(t, log.WarnLevel, e.Level)
+	assert.Equal(t, "Worker timeout", e.Message)
+	assert.Equal(t, int64(1521021600), e.Timestamp.Unix())
+	assert.Equal(t, log.Fields{"pid": float64(1234)}, e.Fields)
+}
+
+func TestSpringBoot(t *testing.T) {
+	e, ok := Auto.Parse(`2018-03-14 10:00:00.123  INFO 1234 --- [           main] c.e.demo.DemoApplication                 : Started DemoApplication in 2.5 seconds`)
+	assert.True(t, ok, "ok")
+	assert.Equal(t, log.InfoLevel, e.Level)
+	assert.Equal(t, "Started DemoApplication in 2.5 seconds", e.Message)
+	assert.Equal(t, log.Fields{
+		"pid":    float64(1234),
+		"thread": "main",
+		"logger": "c.e.demo.DemoApplication",
+	}, e.Fields)
+}
+
+func TestParserFor(t *testing.T) {
+	p, err := ParserFor("logfmt")
+	assert.NoError(t, err, "logfmt")
+
+	_, ok := p.Parse(`{ "level": "info", "message": "hello" }`)
+	assert.False(t, ok, "json")
+
+	_, err = ParserFor("xml")
+	assert.EqualError(t, err, `unknown format "xml"`)
+}
@@ -1,33 +1,90 @@
 // Package writer provides an io.Writer for capturing
 // process output as logs, so that stdout may become
 // INFO, and stderr ERROR.
+//
+// Lines in a structured format such as JSON or logfmt
+// are parsed to preserve their level and fields, while
+// the level of plain text is detected from leading
+// markers such as "WARN", "[error]" or "<3>".
+//
+// Multiline output such as stack traces is coalesced
+// into a single entry with a "stack" field. Lines which
+// may begin a stack trace, such as "Error: boom", are
+// held until the next entry begins, or the output is
+// idle for a short period, other lines are written
+// immediately.
 package writer
 
 import (
 	"bufio"
 	"bytes"
-	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/apex/log"
+	"github.com/apex/up/internal/logs/redact"
 	"github.com/apex/up/internal/util"
 )
 
+// idleTimeout is the delay without output after which a pending entry is written.
+var idleTimeout = 100 * time.Millisecond
+
+// starts matches lines which may begin a multiline entry such as a stack trace.
+var starts = regexp.MustCompile(`(?i)error|exception|panic|fatal|traceback`)
+
+// more matches the elided frames line of Java stack traces, "... 5 more".
+var more = regexp.MustCompile(`^\.\.\. \d+ more`)
+
 // Writer struct.
 type Writer struct {
-	log   log.Interface
-	level log.Level
+	log    log.Interface
+	level  log.Level
+	parser Parser
+	levels Levels
+	redact *redact.Redactor
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	pending   *log.Entry
+	stack     []string
+	traceback bool
 }
 
-// New writer with the given log level.
+// New writer with the given log level, detecting the format of lines.
 func New(l log.Level, ctx log.Interface) *Writer {
 	return &Writer{
-		log:   ctx,
-		level: l,
+		log:    ctx,
+		level:  l,
+		parser: Auto,
 	}
 }
 
+// WithParser sets the parser used for lines of output.
+func (w *Writer) WithParser(p Parser) *Writer {
+	w.parser = p
+	return w
+}
+
+// WithRedactor sets the redactor applied to messages and fields.
+func (w *Writer) WithRedactor(r *redact.Redactor) *Writer {
+	w.redact = r
+	return w
+}
+
+// WithLevels sets the level patterns used for plain text
+// lines, in addition to the built-in markers.
+func (w *Writer) WithLevels(l Levels) *Writer {
+	w.levels = l
+	return w
+}
+
 // Write implementation.
 func (w *Writer) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	s := bufio.NewScanner(bytes.NewReader(b))
 
 	for s.Scan() {
@@ -40,52 +97,182 @@ func (w *Writer) Write(b []byte) (int, error) {
 		return 0, err
 	}
 
+	if w.pending != nil {
+		w.schedule()
+	}
+
 	return len(b), nil
 }
 
+// Flush the pending entry, if any.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
 // write the line.
 func (w *Writer) write(s string) error {
-	if util.IsJSONLog(s) {
-		return w.writeJSON(s)
+	e, ok := w.parser.Parse(s)
+
+	if !ok && w.pending != nil && w.isContinuation(s) {
+		w.stack = append(w.stack, s)
+		return nil
 	}
 
-	return w.writeText(s)
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	// structured loggers include stack traces as fields
+	if ok && util.IsJSON(s) {
+		return w.writeEntry(w.log, e)
+	}
+
+	if !ok {
+		e = w.text(s)
+	}
+
+	if !starts.MatchString(s) {
+		return w.writeEntry(w.log, e)
+	}
+
+	w.pending = e
+	w.traceback = isTraceback(s)
+	return nil
 }
 
-// writeJSON writes a json log, interpreting it as a log.Entry.
-func (w *Writer) writeJSON(s string) error {
-	// TODO: make this less ugly in apex/log,
-	// you should be able to write an arbitrary Entry.
-	var e log.Entry
+// text returns an entry for a plain text line, with the
+// detected level, or the writer's level by default.
+func (w *Writer) text(s string) *log.Entry {
+	level, msg, ok := w.levels.Detect(s)
+	if !ok {
+		level = w.level
+	}
+
+	return &log.Entry{
+		Level:   level,
+		Message: msg,
+	}
+}
+
+// isContinuation returns true if the line continues the pending
+// entry, such as an indented frame of a stack trace.
+func (w *Writer) isContinuation(s string) bool {
+	if isTraceback(s) {
+		w.traceback = true
+		return true
+	}
 
-	if err := json.Unmarshal([]byte(s), &e); err != nil {
-		return w.writeText(s)
+	if s != "" && (s[0] == ' ' || s[0] == '\t') {
+		return true
 	}
 
+	// Python tracebacks end with an unindented exception line
+	if w.traceback {
+		w.traceback = false
+		return true
+	}
+
+	return strings.HasPrefix(s, "at ") ||
+		strings.HasPrefix(s, "Caused by:") ||
+		more.MatchString(s)
+}
+
+// isTraceback returns true if the line begins a Python traceback.
+func isTraceback(s string) bool {
+	return strings.HasPrefix(s, "Traceback (most recent call last)")
+}
+
+// schedule a flush once idle, the caller must hold the lock.
+func (w *Writer) schedule() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+
+	w.timer = time.AfterFunc(idleTimeout, func() {
+		w.Flush()
+	})
+}
+
+// flush the pending entry, the caller must hold the lock.
+func (w *Writer) flush() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	if w.pending == nil {
+		return nil
+	}
+
+	ctx := w.log
+	if len(w.stack) > 0 {
+		ctx = ctx.WithField("stack", w.redact.String(strings.Join(w.stack, "\n")))
+	}
+
+	err := w.writeEntry(ctx, w.pending)
+	w.pending = nil
+	w.traceback = false
+	w.stack = nil
+	return err
+}
+
+// writeEntry writes the entry with its level, fields and timestamp.
+func (w *Writer) writeEntry(ctx log.Interface, e *log.Entry) error {
+	// TODO: make this less ugly in apex/log,
+	// you should be able to write an arbitrary Entry.
+	ctx = at(ctx, e.Timestamp).WithFields(w.redact.Fields(e.Fields))
+	msg := w.redact.String(e.Message)
+
 	switch e.Level {
 	case log.DebugLevel:
-		w.log.WithFields(e.Fields).Debug(e.Message)
+		ctx.Debug(msg)
 	case log.InfoLevel:
-		w.log.WithFields(e.Fields).Info(e.Message)
+		ctx.Info(msg)
 	case log.WarnLevel:
-		w.log.WithFields(e.Fields).Warn(e.Message)
+		ctx.Warn(msg)
 	case log.ErrorLevel:
-		w.log.WithFields(e.Fields).Error(e.Message)
+		ctx.Error(msg)
 	case log.FatalLevel:
 		// TODO: FATAL without exit...
-		w.log.WithFields(e.Fields).Error(e.Message)
+		ctx.Error(msg)
 	}
 
 	return nil
 }
 
-// writeText writes plain text.
-func (w *Writer) writeText(s string) error {
-	switch w.level {
-	case log.InfoLevel:
-		w.log.Info(s)
-	case log.ErrorLevel:
-		w.log.Error(s)
+// at returns ctx logging entries with the timestamp t
+// instead of the current time, unless t is zero.
+func at(ctx log.Interface, t time.Time) log.Interface {
+	if t.IsZero() {
+		return ctx
 	}
-	return nil
+
+	var logger *log.Logger
+
+	switch v := ctx.(type) {
+	case *log.Logger:
+		logger = v
+	case *log.Entry:
+		logger = v.Logger
+	default:
+		return ctx
+	}
+
+	l := &log.Logger{
+		Level: logger.Level,
+		Handler: log.HandlerFunc(func(e *log.Entry) error {
+			e.Timestamp = t
+			return logger.Handler.HandleLog(e)
+		}),
+	}
+
+	if e, ok := ctx.(*log.Entry); ok {
+		c := *e
+		c.Logger = l
+		return &c
+	}
+
+	return l
 }
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/json"
 	"github.com/tj/assert"
+
+	"github.com/apex/up/config"
+	"github.com/apex/up/internal/logs/redact"
 )
 
 func init() {
@@ -34,6 +38,7 @@ POST /logout
 
 	_, err := io.Copy(w, strings.NewReader(input))
 	assert.NoError(t, err, "copy")
+	assert.NoError(t, w.Flush(), "flush")
 
 	expected := `{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
 {"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"GET /account"}
@@ -67,3 +72,237 @@ func TestWriter_json(t *testing.T) {
 
 	assert.Equal(t, expected, buf.String())
 }
+
+func TestWriter_stack(t *testing.T) {
+	t.Run("node", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetHandler(json.New(&buf))
+		w := New(log.ErrorLevel, log.Log)
+
+		input := `Error: boom
+    at Server.<anonymous> (/app/app.js:10:9)
+    at emitTwo (events.js:126:13)
+GET /
+`
+
+		_, err := io.Copy(w, strings.NewReader(input))
+		assert.NoError(t, err, "copy")
+		assert.NoError(t, w.Flush(), "flush")
+
+		expected := `{"fields":{"stack":"    at Server.\u003canonymous\u003e (/app/app.js:10:9)\n    at emitTwo (events.js:126:13)"},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"Error: boom"}
+{"fields":{},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
+`
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("java", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetHandler(json.New(&buf))
+		w := New(log.ErrorLevel, log.Log)
+
+		input := `Exception in thread "main" java.lang.IllegalStateException: boom
+	at com.example.App.run(App.java:12)
+Caused by: java.lang.NullPointerException
+	at com.example.App.load(App.java:20)
+	... 3 more
+`
+
+		_, err := io.Copy(w, strings.NewReader(input))
+		assert.NoError(t, err, "copy")
+		assert.NoError(t, w.Flush(), "flush")
+
+		expected := `{"fields":{"stack":"\tat com.example.App.run(App.java:12)\nCaused by: java.lang.NullPointerException\n\tat com.example.App.load(App.java:20)\n\t... 3 more"},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"Exception in thread \"main\" java.lang.IllegalStateException: boom"}
+`
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("python", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetHandler(json.New(&buf))
+		w := New(log.ErrorLevel, log.Log)
+
+		input := `Traceback (most recent call last):
+  File "app.py", line 3, in <module>
+    main()
+ValueError: boom
+GET /
+`
+
+		_, err := io.Copy(w, strings.NewReader(input))
+		assert.NoError(t, err, "copy")
+		assert.NoError(t, w.Flush(), "flush")
+
+		expected := `{"fields":{"stack":"  File \"app.py\", line 3, in \u003cmodule\u003e\n    main()\nValueError: boom"},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"Traceback (most recent call last):"}
+{"fields":{},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
+`
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("split writes", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetHandler(json.New(&buf))
+		w := New(log.ErrorLevel, log.Log)
+
+		io.WriteString(w, "Error: boom\n")
+		io.WriteString(w, "    at main (app.js:1:1)\n")
+		assert.NoError(t, w.Flush(), "flush")
+
+		expected := `{"fields":{"stack":"    at main (app.js:1:1)"},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"Error: boom"}
+`
+
+		assert.Equal(t, expected, buf.String())
+	})
+}
+
+func TestWriter_idle(t *testing.T) {
+	var buf bytes.Buffer
+	var mu sync.Mutex
+
+	log.SetHandler(json.New(writerFunc(func(b []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return buf.Write(b)
+	})))
+
+	w := New(log.InfoLevel, log.Log)
+
+	read := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return buf.String()
+	}
+
+	io.WriteString(w, "GET /\n")
+	assert.Equal(t, `{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
+`, read(), "single line")
+
+	io.WriteString(w, "Error: boom\n")
+	assert.Equal(t, `{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
+`, read(), "before idle")
+
+	time.Sleep(idleTimeout * 3)
+	assert.Equal(t, `{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
+{"fields":{},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"Error: boom"}
+`, read(), "after idle")
+}
+
+func TestWriter_timestamp(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetHandler(json.New(&buf))
+
+	w := New(log.InfoLevel, log.Log.WithField("plugin", "relay"))
+
+	input := `{"level":"info","message":"request","timestamp":"2018-03-14T10:00:00Z"}
+level=warn msg="slow request" time=2018-03-14T10:00:01Z
+`
+
+	_, err := io.Copy(w, strings.NewReader(input))
+	assert.NoError(t, err, "copy")
+	assert.NoError(t, w.Flush(), "flush")
+
+	expected := `{"fields":{"plugin":"relay"},"level":"info","timestamp":"2018-03-14T10:00:00Z","message":"request"}
+{"fields":{"plugin":"relay"},"level":"warn","timestamp":"2018-03-14T10:00:01Z","message":"slow request"}
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+// writerFunc is an io.Writer function.
+type writerFunc func([]byte) (int, error)
+
+// Write implementation.
+func (f writerFunc) Write(b []byte) (int, error) {
+	return f(b)
+}
+
+func TestWriter_logfmt(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetHandler(json.New(&buf))
+
+	w := New(log.InfoLevel, log.Log).WithParser(Logfmt)
+
+	input := `level=warn msg="slow request" user=42 path=/login
+level=error msg="request failed"
+	at handler (app.js:10:5)
+GET /
+`
+
+	_, err := io.Copy(w, strings.NewReader(input))
+	assert.NoError(t, err, "copy")
+	assert.NoError(t, w.Flush(), "flush")
+
+	expected := `{"fields":{"path":"/login","user":42},"level":"warn","timestamp":"1970-01-01T00:00:00Z","message":"slow request"}
+{"fields":{"stack":"\tat handler (app.js:10:5)"},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"request failed"}
+{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"GET /"}
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriter_levels(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetHandler(json.New(&buf))
+
+	levels, err := CompileLevels(map[string][]string{
+		"warn": {"DeprecationWarning"},
+	})
+	assert.NoError(t, err, "levels")
+
+	w := New(log.ErrorLevel, log.Log).WithLevels(levels)
+
+	input := `(node:1) DeprecationWarning: Buffer() is deprecated
+INFO listening
+<4>low memory
+boom
+`
+
+	_, err = io.Copy(w, strings.NewReader(input))
+	assert.NoError(t, err, "copy")
+	assert.NoError(t, w.Flush(), "flush")
+
+	expected := `{"fields":{},"level":"warn","timestamp":"1970-01-01T00:00:00Z","message":"(node:1) DeprecationWarning: Buffer() is deprecated"}
+{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"INFO listening"}
+{"fields":{},"level":"warn","timestamp":"1970-01-01T00:00:00Z","message":"low memory"}
+{"fields":{},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"boom"}
+`
+
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriter_redact(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetHandler(json.New(&buf))
+
+	r, err := redact.New(config.Redact{
+		Fields:   []string{"email"},
+		Patterns: []string{`token=\w+`},
+		Mode:     "mask",
+	})
+	assert.NoError(t, err, "redactor")
+
+	w := New(log.InfoLevel, log.Log).WithRedactor(r)
+
+	input := `{ "level": "info", "message": "signup", "fields": { "email": "tobi@apex.sh", "plan": "pro" } }
+requested /?token=abc
+Error: token=abc rejected
+    at verify (auth.js:5:3) token=abc
+`
+
+	_, err = io.Copy(w, strings.NewReader(input))
+	assert.NoError(t, err, "copy")
+	assert.NoError(t, w.Flush(), "flush")
+
+	expected := `{"fields":{"email":"[REDACTED]","plan":"pro"},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"signup"}
+{"fields":{},"level":"info","timestamp":"1970-01-01T00:00:00Z","message":"requested /?[REDACTED]"}
+{"fields":{"stack":"    at verify (auth.js:5:3) [REDACTED]"},"level":"error","timestamp":"1970-01-01T00:00:00Z","message":"Error: [REDACTED] rejected"}
+`
+
+	assert.Equal(t, expected, buf.String())
+}
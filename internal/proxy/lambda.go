@@ -2,6 +2,7 @@
 package proxy
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -9,6 +10,21 @@ import (
 	"github.com/pkg/errors"
 )
 
+// lambdaRequestID is the context key of the invocation's request id.
+type lambdaRequestID struct{}
+
+// WithLambdaRequestID returns a context with the request id of a Lambda invocation.
+func WithLambdaRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, lambdaRequestID{}, id)
+}
+
+// LambdaRequestID returns the request id of the Lambda invocation
+// serving the request, or an empty string when there is none.
+func LambdaRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(lambdaRequestID{}).(string)
+	return id
+}
+
 // NewHandler returns an apex.Handler.
 func NewHandler(h http.Handler) apex.Handler {
 	return apex.HandlerFunc(func(event json.RawMessage, ctx *apex.Context) (interface{}, error) {
@@ -24,6 +40,11 @@ func NewHandler(h http.Handler) apex.Handler {
 			return nil, errors.Wrap(err, "creating new request from event")
 		}
 
+		// correlates request logs with the invocation's REPORT line
+		if ctx != nil {
+			req = req.WithContext(WithLambdaRequestID(req.Context(), ctx.RequestID))
+		}
+
 		res := NewResponse()
 		h.ServeHTTP(res, req)
 		return res.End(), nil
@@ -0,0 +1,24 @@
+package proxy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/apex/go-apex"
+	"github.com/tj/assert"
+)
+
+func TestNewHandler(t *testing.T) {
+	var id, header string
+
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = LambdaRequestID(r.Context())
+		header = r.Header.Get("X-Lambda-Request-Id")
+	}))
+
+	_, err := h.Handle([]byte(getEvent), &apex.Context{RequestID: "3f5c0a4e"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "3f5c0a4e", id)
+	assert.Empty(t, header, "header")
+}
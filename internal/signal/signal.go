@@ -15,7 +15,7 @@ var fns []Func
 func init() {
 	s := make(chan os.Signal, 1)
 	go trap(s)
-	signal.Notify(s, syscall.SIGINT)
+	signal.Notify(s, syscall.SIGINT, syscall.SIGTERM)
 }
 
 // Func is a close function.
@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -40,6 +41,60 @@ func ClearHeader(h http.Header) {
 	h.Del("Last-Modified")
 }
 
+// IsUpgrade returns true if the request is a protocol
+// upgrade, such as a WebSocket handshake.
+func IsUpgrade(r *http.Request) bool {
+	if r.Header.Get("Upgrade") == "" {
+		return false
+	}
+
+	for _, v := range r.Header["Connection"] {
+		for _, s := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), "upgrade") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// streaming content types.
+var streaming = []string{
+	"text/event-stream",
+	"application/x-ndjson",
+	"multipart/x-mixed-replace",
+}
+
+// IsStreaming returns true if the media type is
+// delivered incrementally, such as Server-Sent Events.
+func IsStreaming(kind string) bool {
+	for _, s := range streaming {
+		if strings.HasPrefix(kind, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Flush `w`, if supported.
+func Flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack the connection of `w`, if supported.
+func Hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker is not supported")
+	}
+
+	return h.Hijack()
+}
+
 // ManagedByUp appends "Managed by Up".
 func ManagedByUp(s string) string {
 	if s == "" {
@@ -200,6 +255,20 @@ func Indent(s string) string {
 
 // WaitForListen blocks until `u` is listening with timeout.
 func WaitForListen(u *url.URL, timeout time.Duration) error {
+	return waitFor(func() bool {
+		return IsListening(u)
+	}, timeout)
+}
+
+// WaitForSocket blocks until the unix socket at `path` is listening with timeout.
+func WaitForSocket(path string, timeout time.Duration) error {
+	return waitFor(func() bool {
+		return IsSocketListening(path)
+	}, timeout)
+}
+
+// waitFor blocks until `ok` returns true with timeout.
+func waitFor(ok func() bool, timeout time.Duration) error {
 	timedout := time.After(timeout)
 
 	b := backoff.Backoff{
@@ -213,7 +282,7 @@ func WaitForListen(u *url.URL, timeout time.Duration) error {
 		case <-timedout:
 			return errors.Errorf("timed out after %s", timeout)
 		case <-time.After(b.Duration()):
-			if IsListening(u) {
+			if ok() {
 				return nil
 			}
 		}
@@ -222,7 +291,17 @@ func WaitForListen(u *url.URL, timeout time.Duration) error {
 
 // IsListening returns true if there's a server listening on `u`.
 func IsListening(u *url.URL) bool {
-	conn, err := net.Dial("tcp", u.Host)
+	return isDialable("tcp", u.Host)
+}
+
+// IsSocketListening returns true if there's a server listening on the unix socket at `path`.
+func IsSocketListening(path string) bool {
+	return isDialable("unix", path)
+}
+
+// isDialable returns true if a connection to `addr` succeeds.
+func isDialable(network, addr string) bool {
+	conn, err := net.Dial(network, addr)
 	if err != nil {
 		return false
 	}
@@ -368,6 +447,87 @@ func ParseDuration(s string) (d time.Duration, err error) {
 	return
 }
 
+// dateLayouts are the layouts of absolute times accepted by ParseTime.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// clockLayouts are the layouts of times of day accepted by ParseTime.
+var clockLayouts = []string{
+	"15:04:05",
+	"15:04",
+	"3:04pm",
+	"3pm",
+}
+
+// ParseTime returns the time for a duration before now such as "5m" or
+// "3d", an absolute time such as "2018-01-15 14:00" or RFC3339, or a time
+// of day, optionally prefixed by "today" or "yesterday" such as
+// "yesterday 14:00". Times without a zone are in the location of now.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if d, err := ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	day := now
+	clock := strings.Fields(strings.ToLower(s))
+	switch {
+	case len(clock) > 0 && clock[0] == "today":
+		clock = clock[1:]
+	case len(clock) > 0 && clock[0] == "yesterday":
+		day = now.AddDate(0, 0, -1)
+		clock = clock[1:]
+	}
+
+	y, m, d := day.Date()
+
+	if len(clock) == 0 {
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, strings.Join(clock, " ")); err == nil {
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+
+	return time.Time{}, errors.Errorf("%q must be a duration such as 5m, a time such as 2018-01-15T14:00:00Z, or a time of day such as \"yesterday 14:00\"", s)
+}
+
+// FormatCursor returns the logs cursor t as an RFC3339 time with nanoseconds.
+func FormatCursor(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ParseCursor returns the time of a logs cursor, which is an RFC3339
+// time with optional nanoseconds, or milliseconds since the Unix epoch.
+func ParseCursor(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, errors.Errorf("%q must be a time such as 2018-01-01T10:00:00.123Z or milliseconds since the epoch", s)
+}
+
 // Md5 returns an md5 hash for s.
 func Md5(s string) string {
 	h := md5.New()
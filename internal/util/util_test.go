@@ -1,8 +1,12 @@
 package util
 
 import (
+	"io/ioutil"
+	"net"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -76,6 +80,63 @@ func TestParseDuration(t *testing.T) {
 	})
 }
 
+func TestParseTime(t *testing.T) {
+	now := time.Date(2018, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		input string
+		time  time.Time
+	}{
+		{"5m", time.Date(2018, 1, 15, 10, 25, 0, 0, time.UTC)},
+		{"1d", time.Date(2018, 1, 14, 10, 30, 0, 0, time.UTC)},
+		{"2018-01-10T08:00:00Z", time.Date(2018, 1, 10, 8, 0, 0, 0, time.UTC)},
+		{"2018-01-10T08:00:00-08:00", time.Date(2018, 1, 10, 16, 0, 0, 0, time.UTC)},
+		{"2018-01-10 08:15", time.Date(2018, 1, 10, 8, 15, 0, 0, time.UTC)},
+		{"2018-01-10", time.Date(2018, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{"today", time.Date(2018, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Date(2018, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"yesterday 14:00", time.Date(2018, 1, 14, 14, 0, 0, 0, time.UTC)},
+		{"Yesterday 2pm", time.Date(2018, 1, 14, 14, 0, 0, 0, time.UTC)},
+		{"today 9:05:30", time.Date(2018, 1, 15, 9, 5, 30, 0, time.UTC)},
+		{"08:00", time.Date(2018, 1, 15, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		v, err := ParseTime(c.input, now)
+		assert.NoError(t, err, c.input)
+		assert.True(t, c.time.Equal(v), "%s: expected %s, got %s", c.input, c.time, v)
+	}
+
+	_, err := ParseTime("last week", now)
+	assert.EqualError(t, err, `"last week" must be a duration such as 5m, a time such as 2018-01-15T14:00:00Z, or a time of day such as "yesterday 14:00"`)
+}
+
+func TestParseCursor(t *testing.T) {
+	t.Run("round-trip", func(t *testing.T) {
+		c := time.Date(2018, 1, 1, 10, 0, 0, 123456789, time.UTC)
+		v, err := ParseCursor(FormatCursor(c))
+		assert.NoError(t, err)
+		assert.True(t, c.Equal(v), "expected %s, got %s", c, v)
+	})
+
+	t.Run("zone", func(t *testing.T) {
+		v, err := ParseCursor("2018-01-01T02:00:00.5-08:00")
+		assert.NoError(t, err)
+		assert.Equal(t, "2018-01-01T10:00:00.5Z", FormatCursor(v))
+	})
+
+	t.Run("milliseconds", func(t *testing.T) {
+		v, err := ParseCursor("1514800800123")
+		assert.NoError(t, err)
+		assert.Equal(t, "2018-01-01T10:00:00.123Z", FormatCursor(v))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseCursor("yesterday")
+		assert.EqualError(t, err, `"yesterday" must be a time such as 2018-01-01T10:00:00.123Z or milliseconds since the epoch`)
+	})
+}
+
 func TestDomain(t *testing.T) {
 	assert.Equal(t, "example.com", Domain("example.com"))
 	assert.Equal(t, "example.com", Domain("api.example.com"))
@@ -159,3 +220,40 @@ func TestBinaryCase(t *testing.T) {
 		}
 	}
 }
+
+func TestIsSocketListening(t *testing.T) {
+	dir, err := ioutil.TempDir("", "up")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.sock")
+	assert.False(t, IsSocketListening(path), "before listening")
+
+	l, err := net.Listen("unix", path)
+	assert.NoError(t, err, "listen")
+	defer l.Close()
+
+	assert.True(t, IsSocketListening(path), "listening")
+	assert.NoError(t, WaitForSocket(path, time.Second), "wait")
+}
+
+func TestIsUpgrade(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	assert.False(t, IsUpgrade(r), "plain")
+
+	r.Header.Set("Upgrade", "websocket")
+	assert.False(t, IsUpgrade(r), "missing connection")
+
+	r.Header.Set("Connection", "keep-alive, Upgrade")
+	assert.True(t, IsUpgrade(r), "upgrade")
+
+	r.Header.Del("Upgrade")
+	assert.False(t, IsUpgrade(r), "missing upgrade")
+}
+
+func TestIsStreaming(t *testing.T) {
+	assert.True(t, IsStreaming("text/event-stream"))
+	assert.True(t, IsStreaming("application/x-ndjson; charset=utf-8"))
+	assert.False(t, IsStreaming("text/html"))
+	assert.False(t, IsStreaming(""))
+}
@@ -0,0 +1,218 @@
+// Package watch provides polling of a directory tree
+// for changes, debouncing them into batches.
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tj/go-archive"
+)
+
+// file state used to detect changes.
+type file struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher polls files in a directory for changes.
+type Watcher struct {
+	// Dir is the root directory watched.
+	Dir string
+
+	// Paths matches the files watched, all files are watched when nil.
+	Paths archive.Filter
+
+	// Ignore matches the files and directories ignored.
+	Ignore archive.Filter
+
+	// Interval between polls.
+	Interval time.Duration
+
+	// Debounce is the period without changes after which they are reported.
+	Debounce time.Duration
+
+	files map[string]file
+	reset chan chan struct{}
+	quit  chan struct{}
+	once  sync.Once
+}
+
+// Start polling, the changed paths are sent in batches until stopped.
+func (w *Watcher) Start() (<-chan []string, error) {
+	files, err := w.scan()
+	if err != nil {
+		return nil, errors.Wrap(err, "scanning")
+	}
+
+	w.files = files
+	w.reset = make(chan chan struct{})
+	w.quit = make(chan struct{})
+	ch := make(chan []string)
+	go w.loop(ch)
+	return ch, nil
+}
+
+// Stop polling.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.quit)
+	})
+}
+
+// Reset discards the changes not yet reported, such as the output
+// of a build run while reloading, and polls from the current state
+// once it returns.
+func (w *Watcher) Reset() {
+	done := make(chan struct{})
+
+	select {
+	case w.reset <- done:
+		<-done
+	case <-w.quit:
+	}
+}
+
+// loop polls until stopped, sending batches of changes once
+// no further changes were seen for the debounce period.
+func (w *Watcher) loop(ch chan<- []string) {
+	defer close(ch)
+
+	tick := time.NewTicker(w.Interval)
+	defer tick.Stop()
+
+	pending := make(map[string]bool)
+	var last time.Time
+
+	for {
+		select {
+		case <-w.quit:
+			return
+		case done := <-w.reset:
+			w.rescan()
+			pending = make(map[string]bool)
+			close(done)
+			continue
+		case <-tick.C:
+		}
+
+		files, err := w.scan()
+		if err != nil {
+			continue
+		}
+
+		changed := diff(w.files, files)
+		w.files = files
+
+		for _, path := range changed {
+			pending[path] = true
+			last = time.Now()
+		}
+
+		if len(pending) == 0 || time.Since(last) < w.Debounce {
+			continue
+		}
+
+		var batch []string
+		for path := range pending {
+			batch = append(batch, path)
+		}
+		sort.Strings(batch)
+		pending = make(map[string]bool)
+
+		select {
+		case ch <- batch:
+		case done := <-w.reset:
+			w.rescan()
+			close(done)
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// rescan updates the state of the watched files without reporting changes.
+func (w *Watcher) rescan() {
+	if files, err := w.scan(); err == nil {
+		w.files = files
+	}
+}
+
+// scan returns the state of the watched files.
+func (w *Watcher) scan() (map[string]file, error) {
+	files := make(map[string]file)
+
+	err := filepath.Walk(w.Dir, func(path string, info os.FileInfo, err error) error {
+		// files may be removed while walking
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(w.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		// filters match paths relative to the directory
+		fi := archive.Info{
+			Name:     rel,
+			Size:     info.Size(),
+			Mode:     info.Mode(),
+			Modified: info.ModTime(),
+			Dir:      info.IsDir(),
+		}.FileInfo()
+
+		if w.Ignore != nil && w.Ignore.Match(fi) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		if w.Paths != nil && !w.Paths.Match(fi) {
+			return nil
+		}
+
+		files[filepath.ToSlash(rel)] = file{
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// diff returns the paths added, removed or modified.
+func diff(prev, next map[string]file) (changed []string) {
+	for path, f := range next {
+		if p, ok := prev[path]; !ok || p != f {
+			changed = append(changed, path)
+		}
+	}
+
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tj/assert"
+	"github.com/tj/go-archive"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "up-watch")
+	assert.NoError(t, err, "tempdir")
+	defer os.RemoveAll(dir)
+
+	write := func(path, s string) {
+		path = filepath.Join(dir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755), "mkdir")
+		assert.NoError(t, ioutil.WriteFile(path, []byte(s), 0644), "write")
+	}
+
+	write("app.js", "a")
+	write("node_modules/mod/index.js", "a")
+
+	ignore, err := archive.FilterPatterns(strings.NewReader("node_modules\n*.log\n"))
+	assert.NoError(t, err, "patterns")
+
+	w := &Watcher{
+		Dir:      dir,
+		Ignore:   ignore,
+		Interval: 10 * time.Millisecond,
+		Debounce: 50 * time.Millisecond,
+	}
+
+	ch, err := w.Start()
+	assert.NoError(t, err, "start")
+	defer w.Stop()
+
+	t.Run("debounce", func(t *testing.T) {
+		write("app.js", "ab")
+		write("lib/util.js", "a")
+		write("node_modules/mod/index.js", "ab")
+		write("debug.log", "a")
+		time.Sleep(20 * time.Millisecond)
+		write("app.js", "abc")
+
+		select {
+		case batch := <-ch:
+			assert.Equal(t, []string{"app.js", "lib/util.js"}, batch)
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.NoError(t, os.Remove(filepath.Join(dir, "lib/util.js")), "remove")
+
+		select {
+		case batch := <-ch:
+			assert.Equal(t, []string{"lib/util.js"}, batch)
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		write("server", "a")
+		w.Reset()
+		write("app.js", "abcd")
+
+		select {
+		case batch := <-ch:
+			assert.Equal(t, []string{"app.js"}, batch)
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		w.Stop()
+
+		select {
+		case _, ok := <-ch:
+			assert.False(t, ok, "closed")
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	})
+}
@@ -27,21 +27,9 @@ var transform = archive.TransformFunc(func(r io.Reader, i os.FileInfo) (io.Reade
 
 // Build the given `dir`.
 func Build(dir string) (io.ReadCloser, *archive.Stats, error) {
-	upignore, err := read(".upignore")
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "reading .upignore")
-	}
-	defer upignore.Close()
-
-	r := io.MultiReader(
-		strings.NewReader(".*\n"),
-		strings.NewReader("\n!node_modules/**\n!.pypath/**\n"),
-		upignore,
-		strings.NewReader("\n!main\n!server\n!_proxy.js\n!byline.js\n!up.json\n!pom.xml\n!build.gradle\n!project.clj\ngin-bin\nup\n"))
-
-	filter, err := archive.FilterPatterns(r)
+	filter, err := Filter()
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "parsing ignore patterns")
+		return nil, nil, err
 	}
 
 	buf := new(bytes.Buffer)
@@ -64,6 +52,31 @@ func Build(dir string) (io.ReadCloser, *archive.Stats, error) {
 	return ioutil.NopCloser(buf), zip.Stats(), nil
 }
 
+// Filter returns a filter matching the files ignored by deploys,
+// using the .upignore file of the current directory, followed by
+// the `extra` patterns.
+func Filter(extra ...string) (archive.Filter, error) {
+	upignore, err := read(".upignore")
+	if err != nil {
+		return nil, errors.Wrap(err, "reading .upignore")
+	}
+	defer upignore.Close()
+
+	r := io.MultiReader(
+		strings.NewReader(".*\n"),
+		strings.NewReader("\n!node_modules/**\n!.pypath/**\n"),
+		upignore,
+		strings.NewReader("\n!main\n!server\n!_proxy.js\n!byline.js\n!up.json\n!pom.xml\n!build.gradle\n!project.clj\ngin-bin\nup\n"),
+		strings.NewReader(strings.Join(extra, "\n")))
+
+	filter, err := archive.FilterPatterns(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing ignore patterns")
+	}
+
+	return filter, nil
+}
+
 // read file.
 func read(path string) (io.ReadCloser, error) {
 	f, err := os.Open(path)
@@ -3,22 +3,39 @@ package up
 import (
 	"io"
 	"time"
+
+	"github.com/apex/log"
 )
 
 // TODO: finalize and finish documentation
 
 // LogsConfig is configuration for viewing logs.
 type LogsConfig struct {
-	// Region is the target region.
-	Region string
+	// Regions is the target regions, logs of
+	// multiple regions are merged.
+	Regions []string
 
 	// Query is the filter pattern.
 	Query string
 
+	// Stages restricts logs to the given stages.
+	Stages []string
+
+	// Request restricts logs to the request of the given id,
+	// including the lines of its Lambda invocation.
+	Request string
+
+	// Group is used to output the logs of each request as a block.
+	Group bool
+
 	// Since is used as the starting point when filtering
 	// historical logs, no logs before this point are returned.
 	Since time.Time
 
+	// Until is used as the end point when filtering
+	// historical logs, no logs after this point are returned.
+	Until time.Time
+
 	// Follow is used to stream new logs.
 	Follow bool
 
@@ -27,6 +44,13 @@ type LogsConfig struct {
 
 	// OutputJSON is used to output raw json.
 	OutputJSON bool
+
+	// NoColor is used to output text without colors.
+	NoColor bool
+
+	// Handler is used to output logs when present,
+	// such as to export them to a file.
+	Handler log.Handler
 }
 
 // Logs is the interface for viewing platform logs.
@@ -34,6 +58,18 @@ type Logs interface {
 	io.Reader
 }
 
+// LogsError is returned when reading logs fails, the logs
+// may be resumed by using Cursor as the Since time.
+type LogsError struct {
+	Err    error
+	Cursor time.Time
+}
+
+// Error implementation.
+func (e *LogsError) Error() string {
+	return e.Err.Error()
+}
+
 // Domains is the interface for purchasing and
 // managing domains names.
 type Domains interface {
@@ -0,0 +1,209 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/pkg/errors"
+
+	"github.com/apex/up/internal/logs/access"
+	"github.com/apex/up/internal/util"
+)
+
+// queryPollInterval is the interval used to poll for query results.
+var queryPollInterval = time.Second
+
+// row is a single Logs Insights result.
+type row []*cloudwatchlogs.ResultField
+
+// get returns the value of field `name`.
+func (r row) get(name string) string {
+	for _, f := range r {
+		if aws.StringValue(f.Field) == name {
+			return aws.StringValue(f.Value)
+		}
+	}
+
+	return ""
+}
+
+// insights runs the query with CloudWatch Logs Insights, rendering a
+// table for stats or fields stages, otherwise the matching log events.
+func (l *Logs) insights() error {
+	if l.Follow {
+		return errors.New("pipeline stages cannot be used when following logs")
+	}
+
+	if len(l.Regions) > 1 {
+		return errors.New("pipeline stages cannot be used with multiple regions")
+	}
+
+	service := retrying{l.service(l.Regions[0])}
+
+	query := l.query.Insights()
+	log.Debugf("insights query %q", query)
+
+	end := l.Until
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	res, err := service.StartQuery(&cloudwatchlogs.StartQueryInput{
+		LogGroupName: &l.group,
+		QueryString:  &query,
+		StartTime:    aws.Int64(l.Since.Unix()),
+		EndTime:      aws.Int64(end.Unix()),
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "starting query")
+	}
+
+	rows, err := results(service, res.QueryId)
+	if err != nil {
+		return err
+	}
+
+	if l.query.Tabular() {
+		return l.table(rows)
+	}
+
+	l.events(rows)
+	return nil
+}
+
+// results polls for the results of query `id` until it completes.
+func results(service cloudwatchlogsiface.CloudWatchLogsAPI, id *string) ([]row, error) {
+	for {
+		res, err := service.GetQueryResults(&cloudwatchlogs.GetQueryResultsInput{
+			QueryId: id,
+		})
+
+		if err != nil {
+			return nil, errors.Wrap(err, "fetching query results")
+		}
+
+		switch status := aws.StringValue(res.Status); status {
+		case cloudwatchlogs.QueryStatusComplete:
+			var rows []row
+			for _, r := range res.Results {
+				rows = append(rows, row(r))
+			}
+			return rows, nil
+		case cloudwatchlogs.QueryStatusFailed, cloudwatchlogs.QueryStatusCancelled:
+			return nil, errors.Errorf("query %s", strings.ToLower(status))
+		}
+
+		time.Sleep(queryPollInterval)
+	}
+}
+
+// table outputs the rows as a table, one JSON object per
+// row, or entries with the row's fields to the handler.
+func (l *Logs) table(rows []row) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// columns are ordered as in the query
+	var columns []string
+	for _, f := range rows[0] {
+		if name := aws.StringValue(f.Field); name != "@ptr" {
+			columns = append(columns, name)
+		}
+	}
+
+	if l.Handler != nil {
+		for _, r := range rows {
+			fields := log.Fields{}
+			for _, c := range columns {
+				fields[column(c)] = r.get(c)
+			}
+
+			e := &log.Entry{Level: log.InfoLevel, Fields: fields}
+			if err := l.Handler.HandleLog(e); err != nil {
+				return errors.Wrap(err, "handling")
+			}
+		}
+		return nil
+	}
+
+	if l.OutputJSON {
+		enc := json.NewEncoder(l.out)
+		for _, r := range rows {
+			m := map[string]string{}
+			for _, c := range columns {
+				m[column(c)] = r.get(c)
+			}
+
+			if err := enc.Encode(m); err != nil {
+				return errors.Wrap(err, "encoding")
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(l.out, 0, 8, 2, ' ', 0)
+
+	var header []string
+	for _, c := range columns {
+		header = append(header, strings.ToUpper(column(c)))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, r := range rows {
+		var values []string
+		for _, c := range columns {
+			values = append(values, r.get(c))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// events outputs the raw messages of the rows as log events.
+func (l *Logs) events(rows []row) {
+	handler := l.handler()
+
+	for _, r := range rows {
+		line := strings.TrimSpace(r.get("@message"))
+
+		if util.IsJSONLog(line) {
+			var e log.Entry
+			if err := json.Unmarshal([]byte(line), &e); err == nil {
+				handler.HandleLog(&e)
+				continue
+			}
+		}
+
+		if skippable(line) {
+			continue
+		}
+
+		if e, ok := access.Parse(line); ok {
+			handler.HandleLog(e)
+			continue
+		}
+
+		ts, _ := time.Parse("2006-01-02 15:04:05.000", r.get("@timestamp"))
+
+		handler.HandleLog(&log.Entry{
+			Timestamp: ts,
+			Level:     log.InfoLevel,
+			Message:   line,
+		})
+	}
+}
+
+// column returns the display name of a result field.
+func column(name string) string {
+	return strings.TrimPrefix(name, "fields.")
+}
@@ -3,9 +3,12 @@ package logs
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/apex/log"
@@ -15,40 +18,61 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/pkg/errors"
 	"github.com/tj/aws/logs"
 
+	"github.com/apex/up/internal/logs/access"
+	"github.com/apex/up/internal/logs/filter"
+	"github.com/apex/up/internal/logs/group"
+	"github.com/apex/up/internal/logs/lambda"
 	"github.com/apex/up/internal/logs/parser"
+	"github.com/apex/up/internal/logs/parser/ast"
 	"github.com/apex/up/internal/logs/text"
 	"github.com/apex/up/internal/util"
 )
 
 // Logs implementation.
 type Logs struct {
+	// skipped is the number of malformed lines,
+	// first for alignment as it is used atomically.
+	skipped int64
+
 	up.LogsConfig
-	group string
-	query string
-	w     io.WriteCloser
+	group   string
+	query   ast.Root
+	service func(region string) cloudwatchlogsiface.CloudWatchLogsAPI
+	out     io.Writer
+	w       *io.PipeWriter
 	io.Reader
 }
 
 // New log tailer.
 func New(group string, c up.LogsConfig) up.Logs {
-	r, w := io.Pipe()
+	return newLogs(group, c, service, os.Stdout)
+}
 
-	query, err := parseQuery(c.Query)
-	if err != nil {
-		w.CloseWithError(err)
-	}
-	log.Debugf("query %q", query)
+// newLogs returns a log tailer using the service
+// function to create clients per region, writing to out.
+func newLogs(group string, c up.LogsConfig, service func(string) cloudwatchlogsiface.CloudWatchLogsAPI, out io.Writer) *Logs {
+	r, w := io.Pipe()
 
 	l := &Logs{
 		LogsConfig: c,
-		query:      query,
 		group:      group,
+		service:    service,
+		out:        out,
 		Reader:     r,
 		w:          w,
 	}
 
+	query, err := parseQuery(c.Query, c.Stages, c.Request)
+	if err != nil {
+		w.CloseWithError(err)
+		return l
+	}
+
+	l.query = query
 	go l.start()
 
 	return l
@@ -56,73 +80,414 @@ func New(group string, c up.LogsConfig) up.Logs {
 
 // start fetching logs.
 func (l *Logs) start() {
+	if l.grouping() && len(l.query.Stages) > 0 {
+		l.w.CloseWithError(errors.New("pipeline stages cannot be used when grouping requests"))
+		return
+	}
+
+	if len(l.query.Stages) > 0 {
+		l.w.CloseWithError(l.insights())
+		return
+	}
+
+	pattern, exact := l.query.Pattern()
+	log.Debugf("query %q", pattern)
+
+	handler := l.handler()
+
+	var requests *group.Handler
+	if l.grouping() {
+		requests = l.requests()
+		handler = requests
+	}
+
+	// filter client-side what the pattern cannot express
+	if !exact {
+		log.Debug("filtering client-side")
+		f, err := filter.New(l.Query)
+		if err != nil {
+			l.w.CloseWithError(err)
+			return
+		}
+		handler = filter.NewHandler(f, handler)
+	}
+
+	err := l.fetch(pattern, handler)
+
+	// the Lambda lines of a request are only
+	// known once its lines have been fetched
+	if err == nil && l.Request != "" {
+		if ids := requests.LambdaIDs(); len(ids) > 0 {
+			err = l.fetch(lambdaPattern(ids), requests)
+		}
+	}
+
+	if requests != nil {
+		requests.Flush()
+	}
+
+	if n := atomic.LoadInt64(&l.skipped); n > 0 {
+		log.Warnf("skipped %d malformed log lines", n)
+	}
+
+	if err != nil {
+		l.w.CloseWithError(err)
+		return
+	}
+
+	l.w.Close()
+}
+
+// fetch passes the logs of all regions matching pattern to handler,
+// merged by timestamp. A *up.LogsError is returned when tailing fails.
+func (l *Logs) fetch(pattern string, handler log.Handler) error {
+	entries := make(chan entry)
+
+	// done stops the tailing of the remaining
+	// regions once merging returns
+	done := make(chan struct{})
+	defer close(done)
+
+	for i, region := range l.Regions {
+		go l.tail(done, i, region, pattern, entries)
+	}
+
+	var delay time.Duration
+	if l.Follow {
+		delay = mergeDelay
+	}
+
+	// cursors of each region, up to which logs are output
+	cursors := make([]time.Time, len(l.Regions))
+	for i := range cursors {
+		cursors[i] = l.Since
+	}
+
+	err := merge(len(l.Regions), entries, delay, func(e entry) {
+		cursors[e.source] = e.at
+		handler.HandleLog(e.Entry)
+	})
+
+	if err != nil {
+		return &up.LogsError{
+			Err:    err,
+			Cursor: earliest(cursors),
+		}
+	}
+
+	return nil
+}
+
+// tail sends the logs of region matching pattern to ch as
+// entries of source i, tagged with the region, until done is closed.
+func (l *Logs) tail(done <-chan struct{}, i int, region, pattern string, ch chan<- entry) {
+	times := &timestamps{CloudWatchLogsAPI: stoppable{retrying{bounded{l.service(region), l.Until}}, done}}
+
 	tailer := logs.New(logs.Config{
-		Service:       cloudwatchlogs.New(session.New(aws.NewConfig().WithRegion(l.Region))),
+		Service:       times,
 		StartTime:     l.Since,
 		PollInterval:  2 * time.Second,
 		Follow:        l.Follow,
-		FilterPattern: l.query,
+		FilterPattern: pattern,
 		GroupNames:    []string{l.group},
 	})
 
-	var handler log.Handler
+	stopped := false
 
-	if l.OutputJSON {
-		handler = jsonlog.New(os.Stdout)
-	} else {
-		handler = text.New(os.Stdout).WithExpandedFields(l.Expand)
+	send := func(e entry) {
+		select {
+		case ch <- e:
+		case <-done:
+			stopped = true
+		}
+	}
+
+	output := func(e *log.Entry, at time.Time) {
+		if e.Fields == nil {
+			e.Fields = log.Fields{}
+		}
+		e.Fields["region"] = region
+		send(entry{source: i, Entry: e, at: at})
 	}
 
 	// TODO: transform to reader of nl-delimited json, move to apex/log?
 	// TODO: marshal/unmarshal as JSON so that numeric values are always float64... remove util.ToFloat()
-	for l := range tailer.Start() {
-		line := strings.TrimSpace(l.Message)
+	for event := range tailer.Start() {
+		at := times.next(event.Timestamp)
+
+		// drain the events so the tailer can stop
+		if stopped {
+			continue
+		}
+
+		line := strings.TrimSpace(event.Message)
 
 		// json log
 		if util.IsJSONLog(line) {
 			var e log.Entry
 			err := json.Unmarshal([]byte(line), &e)
 			if err != nil {
-				log.Fatalf("error parsing json: %s", err)
+				log.Debugf("skipping malformed log line: %s", err)
+				atomic.AddInt64(&l.skipped, 1)
+				continue
 			}
 
-			handler.HandleLog(&e)
+			output(&e, at)
 			continue
 		}
 
+		// lambda START / END / REPORT logs of requests
+		if l.grouping() {
+			if e, ok := lambda.Parse(line); ok {
+				e.Timestamp = at
+				output(e, at)
+				continue
+			}
+		}
+
 		// skip START / END logs since they are redundant
-		if skippable(l.Message) {
+		if skippable(event.Message) {
+			continue
+		}
+
+		// access logs
+		if e, ok := access.Parse(line); ok {
+			output(e, at)
 			continue
 		}
 
 		// lambda textual logs
-		handler.HandleLog(&log.Entry{
-			Timestamp: l.Timestamp,
+		output(&log.Entry{
+			Timestamp: at,
 			Level:     log.InfoLevel,
-			Message:   strings.TrimRight(l.Message, " \n"),
-		})
+			Message:   strings.TrimRight(event.Message, " \n"),
+		}, at)
 	}
 
-	// TODO: refactor interface to delegate
-	if err := tailer.Err(); err != nil {
-		panic(err)
+	var err error
+	if e := tailer.Err(); e != nil {
+		err = errors.Wrapf(e, "tailing %s", region)
 	}
 
-	l.w.Close()
+	if !stopped {
+		send(entry{source: i, err: err})
+	}
 }
 
-// parseQuery parses and converts the query to a CW friendly syntax.
-func parseQuery(s string) (string, error) {
-	if s == "" {
-		return s, nil
+// handler returns the log handler for the output format.
+func (l *Logs) handler() log.Handler {
+	if l.Handler != nil {
+		return l.Handler
 	}
 
-	n, err := parser.Parse(s)
+	if l.OutputJSON {
+		return jsonlog.New(l.out)
+	}
+
+	return l.text()
+}
+
+// text returns the text handler.
+func (l *Logs) text() *text.Handler {
+	return text.New(l.out).
+		WithExpandedFields(l.Expand).
+		WithRegions(len(l.Regions) > 1).
+		WithColors(!l.NoColor)
+}
+
+// requests returns a handler grouping the logs of requests for the output format.
+func (l *Logs) requests() *group.Handler {
+	switch h := l.Handler.(type) {
+	case *text.Handler:
+		return group.New(h.HandleRequest)
+	case log.Handler:
+		return group.New(group.Entries(h))
+	}
+
+	if l.OutputJSON {
+		return group.New(group.JSON(l.out))
+	}
+
+	return group.New(l.text().HandleRequest)
+}
+
+// grouping returns true if the logs of requests are grouped.
+func (l *Logs) grouping() bool {
+	return l.Group || l.Request != ""
+}
+
+// service returns a CloudWatch Logs client for region.
+func service(region string) cloudwatchlogsiface.CloudWatchLogsAPI {
+	return cloudwatchlogs.New(session.New(aws.NewConfig().WithRegion(region)))
+}
+
+// bounded is a CloudWatch Logs client filtering
+// events up to end, unless it is zero.
+type bounded struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	end time.Time
+}
+
+// FilterLogEvents implementation.
+func (s bounded) FilterLogEvents(in *cloudwatchlogs.FilterLogEventsInput) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	if !s.end.IsZero() {
+		in.EndTime = aws.Int64(s.end.UnixNano() / int64(time.Millisecond))
+	}
+
+	return s.CloudWatchLogsAPI.FilterLogEvents(in)
+}
+
+// errStopped is returned to the tailer once tailing is stopped.
+var errStopped = errors.New("stopped")
+
+// stoppable is a CloudWatch Logs client failing
+// requests once done is closed.
+type stoppable struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	done <-chan struct{}
+}
+
+// FilterLogEvents implementation.
+func (s stoppable) FilterLogEvents(in *cloudwatchlogs.FilterLogEventsInput) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	select {
+	case <-s.done:
+		return nil, errStopped
+	default:
+		return s.CloudWatchLogsAPI.FilterLogEvents(in)
+	}
+}
+
+// timestamps is a CloudWatch Logs client recording the millisecond
+// timestamps of the events returned, as the tailer's events are
+// truncated to seconds, which is not precise enough for cursors.
+type timestamps struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	mu   sync.Mutex
+	list []int64
+}
+
+// FilterLogEvents implementation.
+func (s *timestamps) FilterLogEvents(in *cloudwatchlogs.FilterLogEventsInput) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	out, err := s.CloudWatchLogsAPI.FilterLogEvents(in)
 	if err != nil {
-		return "", err
+		return out, err
 	}
 
-	return n.String(), nil
+	s.mu.Lock()
+	for _, e := range out.Events {
+		s.list = append(s.list, aws.Int64Value(e.Timestamp))
+	}
+	s.mu.Unlock()
+
+	return out, nil
+}
+
+// next returns the time of the next event output by the tailer,
+// which are output in the order returned, or t when unknown.
+func (s *timestamps) next(t time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.list) == 0 {
+		return t
+	}
+
+	ms := s.list[0]
+	s.list = s.list[1:]
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// parseQuery parses the query restricted to stages and the request
+// when present, an empty query matches all logs.
+func parseQuery(s string, stages []string, request string) (ast.Root, error) {
+	var root ast.Root
+
+	if s != "" {
+		n, err := parser.Parse(s)
+		if err != nil {
+			return ast.Root{}, err
+		}
+		root = n.(ast.Root)
+	}
+
+	var scope []ast.Node
+
+	switch len(stages) {
+	case 0:
+	case 1:
+		scope = append(scope, ast.Binary{
+			Op:    ast.EQ,
+			Left:  ast.Field("stage"),
+			Right: ast.String(stages[0]),
+		})
+	default:
+		var t ast.Tuple
+		for _, s := range stages {
+			t = append(t, ast.String(s))
+		}
+
+		scope = append(scope, ast.Binary{
+			Op:    ast.IN,
+			Left:  ast.Field("stage"),
+			Right: t,
+		})
+	}
+
+	if request != "" {
+		scope = append(scope, ast.Binary{
+			Op:    ast.EQ,
+			Left:  ast.Field("id"),
+			Right: ast.String(request),
+		})
+	}
+
+	if len(scope) == 0 {
+		return root, nil
+	}
+
+	n := scope[0]
+	for _, s := range scope[1:] {
+		n = ast.Binary{
+			Op:    ast.AND,
+			Left:  n,
+			Right: s,
+		}
+	}
+
+	if root.Node != nil {
+		n = ast.Binary{
+			Op:    ast.AND,
+			Left:  n,
+			Right: ast.Expr{Node: root.Node},
+		}
+	}
+
+	root.Node = n
+	return root, nil
+}
+
+// lambdaPattern returns a filter pattern matching the
+// START, END and REPORT lines of the Lambda request ids.
+func lambdaPattern(ids []string) string {
+	if len(ids) == 1 {
+		return fmt.Sprintf(`"RequestId: %s"`, ids[0])
+	}
+
+	var terms []string
+	for _, id := range ids {
+		terms = append(terms, fmt.Sprintf(`?"RequestId: %s"`, id))
+	}
+	return strings.Join(terms, " ")
+}
+
+// earliest returns the earliest of times.
+func earliest(times []time.Time) (t time.Time) {
+	for i, v := range times {
+		if i == 0 || v.Before(t) {
+			t = v
+		}
+	}
+	return
 }
 
 // skippable returns true if the message is skippable.
@@ -0,0 +1,688 @@
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/tj/assert"
+
+	"github.com/apex/up"
+	"github.com/apex/up/internal/logs/export"
+	"github.com/apex/up/internal/util"
+)
+
+func init() {
+	queryPollInterval = 0
+	retryBackoff.Min = time.Millisecond
+	retryBackoff.Max = time.Millisecond
+}
+
+// fakeService is a CloudWatch Logs client running Logs Insights queries.
+type fakeService struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	query     string
+	polls     int
+	status    string
+	results   [][]*cloudwatchlogs.ResultField
+	pattern   string
+	end       int64
+	events    []string
+	matches   map[string][]string
+	err       error
+	throttles int
+}
+
+// client returns the service for any region.
+func (s *fakeService) client(string) cloudwatchlogsiface.CloudWatchLogsAPI {
+	return s
+}
+
+// FilterLogEvents implementation.
+func (s *fakeService) FilterLogEvents(in *cloudwatchlogs.FilterLogEventsInput) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	s.pattern = aws.StringValue(in.FilterPattern)
+	s.end = aws.Int64Value(in.EndTime)
+
+	if s.throttles > 0 {
+		s.throttles--
+		return nil, awserr.New("ThrottlingException", "Rate exceeded", nil)
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	messages := s.events
+	if m, ok := s.matches[s.pattern]; ok {
+		messages = m
+	}
+
+	var events []*cloudwatchlogs.FilteredLogEvent
+	for _, m := range messages {
+		events = append(events, &cloudwatchlogs.FilteredLogEvent{
+			Timestamp: aws.Int64(1514800800000),
+			Message:   aws.String(m),
+		})
+	}
+
+	return &cloudwatchlogs.FilterLogEventsOutput{Events: events}, nil
+}
+
+// pagedService is a CloudWatch Logs client returning
+// pages of events, failing once they are exhausted.
+type pagedService struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	pages [][]*cloudwatchlogs.FilteredLogEvent
+}
+
+// FilterLogEvents implementation.
+func (s *pagedService) FilterLogEvents(in *cloudwatchlogs.FilterLogEventsInput) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	if len(s.pages) == 0 {
+		return nil, errors.New("boom")
+	}
+
+	events := s.pages[0]
+	s.pages = s.pages[1:]
+	return &cloudwatchlogs.FilterLogEventsOutput{Events: events, NextToken: aws.String("next")}, nil
+}
+
+// StartQuery implementation.
+func (s *fakeService) StartQuery(in *cloudwatchlogs.StartQueryInput) (*cloudwatchlogs.StartQueryOutput, error) {
+	s.query = aws.StringValue(in.QueryString)
+	s.end = aws.Int64Value(in.EndTime)
+	return &cloudwatchlogs.StartQueryOutput{QueryId: aws.String("1")}, nil
+}
+
+// GetQueryResults implementation.
+func (s *fakeService) GetQueryResults(in *cloudwatchlogs.GetQueryResultsInput) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	s.polls++
+
+	if s.polls == 1 {
+		return &cloudwatchlogs.GetQueryResultsOutput{Status: aws.String(cloudwatchlogs.QueryStatusRunning)}, nil
+	}
+
+	return &cloudwatchlogs.GetQueryResultsOutput{
+		Status:  aws.String(s.status),
+		Results: s.results,
+	}, nil
+}
+
+// result returns a result row of field and value pairs.
+func result(pairs ...string) []*cloudwatchlogs.ResultField {
+	var r []*cloudwatchlogs.ResultField
+	for i := 0; i < len(pairs); i += 2 {
+		r = append(r, &cloudwatchlogs.ResultField{
+			Field: aws.String(pairs[i]),
+			Value: aws.String(pairs[i+1]),
+		})
+	}
+	return r
+}
+
+func TestLogs_pattern(t *testing.T) {
+	events := []string{
+		`{"level":"info","message":"response","fields":{"path":"/users/1","status":200}}`,
+		`{"level":"info","message":"response","fields":{"path":"/users/tobi","status":200}}`,
+		`{"level":"info","message":"response","fields":{"path":"/Login","status":200}}`,
+	}
+
+	t.Run("exact", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: events}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Query:      `path matches /^\/users\//`,
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, `{ $.fields.path = "/users/*" }`, s.pattern)
+		assert.Equal(t, 3, strings.Count(buf.String(), "\n"), "the service filters events")
+	})
+
+	t.Run("client-side", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: events}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Query:      `status = 200 path =~ /^\/users\/[0-9]+$/`,
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, `{ $.fields.status = 200 }`, s.pattern)
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		assert.Contains(t, buf.String(), `"path":"/users/1"`)
+	})
+
+	t.Run("stage", func(t *testing.T) {
+		s := &fakeService{}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `status >= 500 or duration > 1s`,
+			Stages:  []string{"production"},
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, `{ $.fields.stage = "production" && ($.fields.status >= 500 || $.fields.duration > 1000) }`, s.pattern)
+	})
+
+	t.Run("stages", func(t *testing.T) {
+		s := &fakeService{}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Stages:  []string{"staging", "production"},
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, `{ ($.fields.stage = "staging" || $.fields.stage = "production") }`, s.pattern)
+	})
+
+	t.Run("case-insensitive", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: events}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Query:      `path ieq "/login"`,
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, ``, s.pattern)
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		assert.Contains(t, buf.String(), `"path":"/Login"`)
+	})
+}
+
+func TestLogs_until(t *testing.T) {
+	until := time.Unix(1514800800, 0)
+
+	t.Run("tail", func(t *testing.T) {
+		s := &fakeService{}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Until:   until,
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, int64(1514800800000), s.end)
+	})
+
+	t.Run("insights", func(t *testing.T) {
+		s := &fakeService{status: cloudwatchlogs.QueryStatusComplete}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `| limit 5`,
+			Until:   until,
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, int64(1514800800), s.end)
+	})
+
+	t.Run("unbounded", func(t *testing.T) {
+		s := &fakeService{}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, int64(0), s.end)
+	})
+}
+
+func TestLogs_handler(t *testing.T) {
+	t.Run("no color", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: []string{
+			`{"level":"info","message":"response","fields":{"status":200}}`,
+		}}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			NoColor: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Contains(t, buf.String(), "INFO response: status=200")
+		assert.NotContains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("events", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: []string{
+			`{"level":"info","message":"response","fields":{"status":200}}`,
+		}}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Handler: export.NewCSV(&buf, []string{"level", "status"}),
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, "level,status\ninfo,200\n", buf.String())
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{
+			status: cloudwatchlogs.QueryStatusComplete,
+			results: [][]*cloudwatchlogs.ResultField{
+				result("fields.path", "/", "count", "120", "@ptr", "a"),
+			},
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `| stats count() by path`,
+			Handler: export.NewCSV(&buf, []string{"path", "count"}),
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, "path,count\n/,120\n", buf.String())
+	})
+}
+
+func TestLogs_group(t *testing.T) {
+	t.Run("group", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: []string{
+			`{"timestamp":"2018-01-01T10:00:00Z","level":"info","message":"initializing"}`,
+			"START RequestId: l1 Version: $LATEST",
+			`{"timestamp":"2018-01-01T10:00:00.100Z","level":"info","message":"request","fields":{"id":"a","lambda_id":"l1"}}`,
+			`{"timestamp":"2018-01-01T10:00:00.112Z","level":"info","message":"response","fields":{"id":"a","lambda_id":"l1","duration":12}}`,
+			"END RequestId: l1",
+			"REPORT RequestId: l1\tDuration: 15.50 ms\tBilled Duration: 100 ms\tMemory Size: 512 MB\tMax Memory Used: 40 MB",
+		}}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Group:      true,
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"message":"initializing"`)
+		assert.NotContains(t, lines[0], `"id"`)
+		assert.Contains(t, lines[1], `{"id":"a","lambda_id":"l1","entries":[`)
+		assert.Contains(t, lines[1], `"message":"start"`)
+		assert.Contains(t, lines[1], `"message":"report"`)
+		assert.Contains(t, lines[1], `"duration":15.5,"billed_duration":100,"memory_used":40,"memory_size":512}`)
+	})
+
+	t.Run("request", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{matches: map[string][]string{
+			`{ $.fields.id = "a" }`: {
+				`{"timestamp":"2018-01-01T10:00:00.100Z","level":"info","message":"request","fields":{"id":"a","lambda_id":"l1"}}`,
+				`{"timestamp":"2018-01-01T10:00:00.112Z","level":"info","message":"response","fields":{"id":"a","lambda_id":"l1","duration":12}}`,
+			},
+			`"RequestId: l1"`: {
+				"START RequestId: l1 Version: $LATEST",
+				"END RequestId: l1",
+				"REPORT RequestId: l1\tDuration: 15.50 ms\tBilled Duration: 100 ms\tMemory Size: 512 MB\tMax Memory Used: 40 MB",
+			},
+		}}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Request:    "a",
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 1)
+		assert.Equal(t, 5, strings.Count(lines[0], `"message":`), "request, response, start, end and report")
+		assert.Contains(t, lines[0], `"billed_duration":100`)
+	})
+
+	t.Run("request pattern", func(t *testing.T) {
+		s := &fakeService{}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `status >= 500`,
+			Stages:  []string{"production"},
+			Request: "a",
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, `{ $.fields.stage = "production" && $.fields.id = "a" && ($.fields.status >= 500) }`, s.pattern)
+	})
+
+	t.Run("insights", func(t *testing.T) {
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `| limit 5`,
+			Group:   true,
+		}, (&fakeService{}).client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.EqualError(t, err, `pipeline stages cannot be used when grouping requests`)
+	})
+}
+
+func TestLogs_errors(t *testing.T) {
+	t.Run("malformed", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{events: []string{
+			`{"level":"info","message":"a"}`,
+			`{"level":"info","message":}`,
+			`{"level":"info","message":"b"}`,
+		}}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+		assert.Equal(t, int64(1), l.skipped)
+	})
+
+	t.Run("throttled", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{
+			events:    []string{`{"level":"info","message":"a"}`},
+			throttles: 3,
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("throttled too often", func(t *testing.T) {
+		s := &fakeService{throttles: 100}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+		}, s.client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.Error(t, err, "read")
+		assert.Contains(t, err.Error(), "ThrottlingException")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		since := time.Unix(1514800000, 0)
+		healthy := &fakeService{events: []string{"hello"}}
+		failed := &fakeService{err: errors.New("boom")}
+
+		client := func(region string) cloudwatchlogsiface.CloudWatchLogsAPI {
+			if region == "us-east-1" {
+				return healthy
+			}
+			return failed
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-east-1", "eu-west-1"},
+			Since:   since,
+		}, client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.EqualError(t, err, `tailing eu-west-1: log "/aws/lambda/app": boom`)
+
+		e, ok := err.(*up.LogsError)
+		assert.True(t, ok, "logs error")
+		assert.Equal(t, since, e.Cursor, "cursor of the earliest region")
+	})
+
+	t.Run("failed cursor milliseconds", func(t *testing.T) {
+		event := func(ms int64, m string) *cloudwatchlogs.FilteredLogEvent {
+			return &cloudwatchlogs.FilteredLogEvent{Timestamp: aws.Int64(ms), Message: aws.String(m)}
+		}
+
+		s := &pagedService{pages: [][]*cloudwatchlogs.FilteredLogEvent{
+			{event(1514800800123, "a"), event(1514800800456, "b")},
+			{event(1514800800789, `{"level":"info","message":"c"}`)},
+		}}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+		}, func(string) cloudwatchlogsiface.CloudWatchLogsAPI { return s }, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.Error(t, err, "read")
+
+		e, ok := err.(*up.LogsError)
+		assert.True(t, ok, "logs error")
+		assert.Equal(t, "2018-01-01T10:00:00.789Z", util.FormatCursor(e.Cursor))
+	})
+
+	t.Run("failed stops other regions", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		var events []string
+		for i := 0; i < 100; i++ {
+			events = append(events, "hello")
+		}
+
+		healthy := &fakeService{events: events}
+		failed := &fakeService{err: errors.New("boom")}
+
+		client := func(region string) cloudwatchlogsiface.CloudWatchLogsAPI {
+			if region == "us-east-1" {
+				return healthy
+			}
+			return failed
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-east-1", "eu-west-1"},
+		}, client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.Error(t, err, "read")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Fatalf("tailing goroutines still running: %d, expected %d", runtime.NumGoroutine(), before)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+func TestLogs_regions(t *testing.T) {
+	services := map[string]*fakeService{
+		"us-east-1": {events: []string{
+			`{"timestamp":"2018-01-01T10:00:02Z","level":"info","message":"a"}`,
+			`{"timestamp":"2018-01-01T10:00:03Z","level":"info","message":"c"}`,
+		}},
+		"eu-west-1": {events: []string{
+			"plain text",
+			`{"timestamp":"2018-01-01T10:00:01Z","level":"info","message":"b"}`,
+			`{"timestamp":"2018-01-01T10:00:05Z","level":"info","message":"d"}`,
+		}},
+	}
+
+	client := func(region string) cloudwatchlogsiface.CloudWatchLogsAPI {
+		return services[region]
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-east-1", "eu-west-1"},
+			OutputJSON: true,
+		}, client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 5)
+		assert.Contains(t, lines[0], `"message":"plain text"`)
+		assert.Contains(t, lines[0], `"region":"eu-west-1"`)
+		assert.Contains(t, lines[1], `"message":"b"`)
+		assert.Contains(t, lines[1], `"region":"eu-west-1"`)
+		assert.Contains(t, lines[2], `"message":"a"`)
+		assert.Contains(t, lines[2], `"region":"us-east-1"`)
+		assert.Contains(t, lines[3], `"message":"c"`)
+		assert.Contains(t, lines[4], `"message":"d"`)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-east-1", "eu-west-1"},
+		}, client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Contains(t, buf.String(), "us-east-1")
+		assert.Contains(t, buf.String(), "eu-west-1")
+	})
+
+	t.Run("insights", func(t *testing.T) {
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-east-1", "eu-west-1"},
+			Query:   `| limit 5`,
+		}, client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.EqualError(t, err, `pipeline stages cannot be used with multiple regions`)
+	})
+}
+
+func TestLogs_insights(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{
+			status: cloudwatchlogs.QueryStatusComplete,
+			results: [][]*cloudwatchlogs.ResultField{
+				result("fields.path", "/", "count", "120", "@ptr", "a"),
+				result("fields.path", "/login", "count", "8", "@ptr", "b"),
+			},
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `status >= 500 | stats count() by path | sort count desc | limit 2`,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+
+		assert.Equal(t, `filter fields.status >= 500 | stats count(*) as count by fields.path | sort count desc | limit 2`, s.query)
+		assert.Equal(t, 2, s.polls)
+		assert.Equal(t, "PATH    COUNT\n/       120\n/login  8\n", buf.String())
+	})
+
+	t.Run("table json", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{
+			status: cloudwatchlogs.QueryStatusComplete,
+			results: [][]*cloudwatchlogs.ResultField{
+				result("fields.id", "1", "fields.status", "200"),
+			},
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Query:      `| fields id, status`,
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+		assert.Equal(t, "{\"id\":\"1\",\"status\":\"200\"}\n", buf.String())
+	})
+
+	t.Run("events", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{
+			status: cloudwatchlogs.QueryStatusComplete,
+			results: [][]*cloudwatchlogs.ResultField{
+				result("@timestamp", "2018-01-01 10:00:00.000", "@message", `{"level":"error","message":"boom","fields":{"id":"1"}}`),
+				result("@timestamp", "2018-01-01 10:00:01.000", "@message", "START RequestId: 123"),
+			},
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions:    []string{"us-west-2"},
+			Query:      `error | limit 5`,
+			OutputJSON: true,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.NoError(t, err, "read")
+
+		assert.Equal(t, `fields @timestamp, @message | filter (level = "error") | limit 5`, s.query)
+		assert.Contains(t, buf.String(), `"message":"boom"`)
+		assert.NotContains(t, buf.String(), "START")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := &fakeService{
+			status: cloudwatchlogs.QueryStatusFailed,
+		}
+
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `| limit 5`,
+		}, s.client, &buf)
+
+		_, err := ioutil.ReadAll(l)
+		assert.EqualError(t, err, `query failed`)
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		l := newLogs("/aws/lambda/app", up.LogsConfig{
+			Regions: []string{"us-west-2"},
+			Query:   `| limit 5`,
+			Follow:  true,
+		}, (&fakeService{}).client, ioutil.Discard)
+
+		_, err := ioutil.ReadAll(l)
+		assert.EqualError(t, err, `pipeline stages cannot be used when following logs`)
+	})
+}
@@ -0,0 +1,113 @@
+package logs
+
+import (
+	"time"
+
+	"github.com/apex/log"
+)
+
+// mergeDelay is the duration entries are held when following logs,
+// allowing the entries of slower sources to be ordered before them.
+var mergeDelay = 3 * time.Second
+
+// entry is a log entry of a source, a nil entry signals
+// the source is done, with err when it failed.
+type entry struct {
+	source int
+	*log.Entry
+
+	// at is the time of the CloudWatch event.
+	at time.Time
+
+	err error
+}
+
+// pending is an entry waiting to be merged.
+type pending struct {
+	entry
+	received time.Time
+}
+
+// merge calls fn with the entries of n sources in timestamp order, assuming
+// the entries of each source are ordered. An entry is passed to fn once every
+// source which is not done has an entry pending, or when delay is non-zero,
+// once it has been held for delay. The first error of a source is returned
+// after passing the pending entries to fn.
+func merge(n int, ch <-chan entry, delay time.Duration, fn func(entry)) error {
+	queues := make([][]pending, n)
+	done := make([]bool, n)
+	remaining := n
+
+	var tick <-chan time.Time
+	if delay > 0 {
+		t := time.NewTicker(delay / 2)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	// flush passes entries to fn in order, stopping at the first
+	// entry which may be preceded by an entry of another source,
+	// unless it was received before cutoff.
+	flush := func(cutoff time.Time) {
+		for {
+			min := -1
+			waiting := false
+
+			for i, q := range queues {
+				switch {
+				case len(q) == 0:
+					waiting = waiting || !done[i]
+				case min == -1 || q[0].Timestamp.Before(queues[min][0].Timestamp):
+					min = i
+				}
+			}
+
+			if min == -1 {
+				return
+			}
+
+			e := queues[min][0]
+
+			if waiting && !e.received.Before(cutoff) {
+				return
+			}
+
+			queues[min] = queues[min][1:]
+			fn(e.entry)
+		}
+	}
+
+	for remaining > 0 {
+		select {
+		case e := <-ch:
+			if e.err != nil {
+				for i := range done {
+					done[i] = true
+				}
+				flush(time.Time{})
+				return e.err
+			}
+
+			if e.Entry == nil {
+				done[e.source] = true
+				remaining--
+			} else {
+				queues[e.source] = append(queues[e.source], pending{
+					entry:    e,
+					received: time.Now(),
+				})
+			}
+		case <-tick:
+		}
+
+		var cutoff time.Time
+		if delay > 0 {
+			cutoff = time.Now().Add(-delay)
+		}
+
+		flush(cutoff)
+	}
+
+	flush(time.Time{})
+	return nil
+}
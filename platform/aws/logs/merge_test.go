@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/tj/assert"
+)
+
+// at returns an entry of source i with message s at second n.
+func at(i int, s string, n int) entry {
+	return entry{
+		source: i,
+		Entry: &log.Entry{
+			Timestamp: time.Unix(int64(n), 0),
+			Message:   s,
+		},
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("ordered", func(t *testing.T) {
+		ch := make(chan entry)
+
+		go func() {
+			ch <- at(0, "a", 1)
+			ch <- at(0, "c", 3)
+			ch <- at(1, "b", 2)
+			ch <- entry{source: 0}
+			ch <- at(1, "d", 4)
+			ch <- entry{source: 1}
+		}()
+
+		var s string
+		err := merge(2, ch, 0, func(e entry) {
+			s += e.Message
+		})
+
+		assert.NoError(t, err, "merge")
+		assert.Equal(t, "abcd", s)
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		ch := make(chan entry)
+		out := make(chan string, 1)
+
+		go merge(2, ch, 10*time.Millisecond, func(e entry) {
+			out <- e.Message
+		})
+
+		// source 1 is idle, so the entry is held for the delay
+		ch <- at(0, "a", 1)
+
+		select {
+		case s := <-out:
+			assert.Equal(t, "a", s)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for entry")
+		}
+
+		ch <- entry{source: 0}
+		ch <- entry{source: 1}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ch := make(chan entry)
+
+		go func() {
+			ch <- at(0, "a", 1)
+			ch <- at(0, "c", 3)
+			ch <- entry{source: 1, err: errors.New("boom")}
+		}()
+
+		var s string
+		err := merge(2, ch, 0, func(e entry) {
+			s += e.Message
+		})
+
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, "ac", s, "pending entries are flushed")
+	})
+}
@@ -0,0 +1,72 @@
+package logs
+
+import (
+	"time"
+
+	"github.com/apex/log"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/tj/backoff"
+)
+
+// retryBackoff is the backoff used to retry throttled requests.
+var retryBackoff = backoff.Backoff{
+	Min:    250 * time.Millisecond,
+	Max:    15 * time.Second,
+	Factor: 2,
+	Jitter: true,
+}
+
+// retryAttempts is the number of retries of throttled requests.
+var retryAttempts = 8
+
+// retrying is a CloudWatch Logs client retrying throttled requests.
+type retrying struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+}
+
+// FilterLogEvents implementation.
+func (s retrying) FilterLogEvents(in *cloudwatchlogs.FilterLogEventsInput) (out *cloudwatchlogs.FilterLogEventsOutput, err error) {
+	err = retry(func() error {
+		out, err = s.CloudWatchLogsAPI.FilterLogEvents(in)
+		return err
+	})
+	return
+}
+
+// StartQuery implementation.
+func (s retrying) StartQuery(in *cloudwatchlogs.StartQueryInput) (out *cloudwatchlogs.StartQueryOutput, err error) {
+	err = retry(func() error {
+		out, err = s.CloudWatchLogsAPI.StartQuery(in)
+		return err
+	})
+	return
+}
+
+// GetQueryResults implementation.
+func (s retrying) GetQueryResults(in *cloudwatchlogs.GetQueryResultsInput) (out *cloudwatchlogs.GetQueryResultsOutput, err error) {
+	err = retry(func() error {
+		out, err = s.CloudWatchLogsAPI.GetQueryResults(in)
+		return err
+	})
+	return
+}
+
+// retry calls fn until it succeeds, fails with an error other than
+// throttling, or the attempts are exhausted.
+func retry(fn func() error) error {
+	b := retryBackoff
+
+	for {
+		err := fn()
+
+		if !request.IsErrorThrottle(err) || int(b.Attempt()) >= retryAttempts {
+			return err
+		}
+
+		d := b.Duration()
+		log.Debugf("throttled, retrying in %s", d)
+		time.Sleep(d)
+	}
+}
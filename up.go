@@ -49,8 +49,11 @@ func (p *Project) WithPlatform(platform Platform) *Project {
 
 // RunHook runs a hook by name.
 func (p *Project) RunHook(name string) error {
-	hook := p.config.Hooks.Get(name)
+	return p.RunCommands(name, p.config.Hooks.Get(name))
+}
 
+// RunCommands runs the commands of `hook` as the hook `name`.
+func (p *Project) RunCommands(name string, hook config.Hook) error {
 	if hook.IsEmpty() {
 		log.Debugf("hook %s is not defined", name)
 		return nil